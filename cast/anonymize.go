@@ -0,0 +1,503 @@
+package cast
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Identity holds what identifies the user and the machine a cast was
+// recorded by.
+type Identity struct {
+	Users []string
+	Hosts []string
+
+	// Homes are home directories (e.g. `/home/alice`).
+	Homes []string
+}
+
+// Merge returns the identity along with the names of `other` it
+// doesn't have yet.
+func (id Identity) Merge(other Identity) Identity {
+	res := Identity{
+		Users: append([]string(nil), id.Users...),
+		Hosts: append([]string(nil), id.Hosts...),
+		Homes: append([]string(nil), id.Homes...),
+	}
+
+	for _, user := range other.Users {
+		res.Users = appendUnique(res.Users, user)
+	}
+
+	for _, host := range other.Hosts {
+		res.Hosts = appendUnique(res.Hosts, host)
+	}
+
+	for _, home := range other.Homes {
+		res.Homes = appendUnique(res.Homes, home)
+	}
+
+	return res
+}
+
+// Placeholders are what `Anonymize` replaces an identity with.
+type Placeholders struct {
+	User string
+	Host string
+
+	// Home replaces home directories; it must be an absolute path.
+	Home string
+
+	// Title, if set, replaces the window titles (OSC 0, 1 and 2) as
+	// a whole instead of just the identity within them.
+	Title string
+}
+
+// DefaultPlaceholders returns the placeholders used when none are
+// configured.
+func DefaultPlaceholders() Placeholders {
+	return Placeholders{
+		User: "user",
+		Host: "host",
+		Home: "/home/user",
+	}
+}
+
+// homePath matches the home directories of regular users.
+var homePath = regexp.MustCompile(`^(?:/Users|/home)/([^/]+)`)
+
+// DetectIdentity finds the user name, host name and home directory a
+// cast was recorded with by looking at the working directories the
+// shell reported (OSC 7 `file://host/path` sequences) and at the
+// `USER`, `LOGNAME`, `HOME` and `HOSTNAME` variables of the header.
+func DetectIdentity(c *Cast) (id Identity, err error) {
+	if c == nil {
+		err = errors.Errorf("cast must not be nil")
+		return
+	}
+
+	addHome := func(path string) {
+		match := homePath.FindStringSubmatch(path)
+		if match != nil {
+			id.Homes = appendUnique(id.Homes, match[0])
+			id.Users = appendUnique(id.Users, match[1])
+		}
+	}
+
+	id.Users = appendUnique(id.Users, c.Header.Env["USER"])
+	id.Users = appendUnique(id.Users, c.Header.Env["LOGNAME"])
+	id.Hosts = appendUnique(id.Hosts, c.Header.Env["HOSTNAME"])
+	addHome(c.Header.Env["HOME"])
+
+	rewriteOSC(c.EventStream, func(body string) string {
+		uri, ok := strings.CutPrefix(body, "7;file://")
+		if !ok {
+			return body
+		}
+
+		host, path := splitFileURI(uri)
+		if host != "localhost" {
+			id.Hosts = appendUnique(id.Hosts, host)
+		}
+		addHome(path)
+
+		return body
+	})
+
+	return
+}
+
+// anonymizedTypes are the types of the events whose printed text gets
+// anonymized (markers are rewritten as a whole, see `Anonymize`).
+var anonymizedTypes = []string{"o", "i"}
+
+// Anonymize replaces the identity `id` with `placeholders` in the
+// output, input and marker events of a cast, returning the number of
+// replacements.
+//
+// Besides printed text (matched across events, see `Replace`), the
+// payloads of OSC sequences are rewritten: the host of the working
+// directories reported with OSC 7 is always replaced, and so are the
+// window titles (OSC 0, 1 and 2) if a title placeholder is set.
+//
+// The header is anonymized as well: the timestamp of the recording is
+// dropped, the title, command and tags are rewritten (so are the
+// strings within the keys that aren't modeled, see `Header.Extra`) and
+// only the `TERM` and `SHELL` variables are kept in the environment.
+func Anonymize(c *Cast, id Identity, placeholders Placeholders) (count int, err error) {
+	if c == nil {
+		err = errors.Errorf("cast must not be nil")
+		return
+	}
+
+	if placeholders.User == "" || placeholders.Host == "" {
+		err = errors.Errorf("user and host placeholders must not be empty")
+		return
+	}
+
+	if !strings.HasPrefix(placeholders.Home, "/") {
+		err = errors.Errorf("home placeholder must be an absolute path")
+		return
+	}
+
+	rules := anonymizeRules(id, placeholders)
+
+	for _, rule := range rules {
+		for _, evType := range anonymizedTypes {
+			var replaced int
+
+			replaced, err = Replace(c, evType, rule.pattern, rule.replacement)
+			if err != nil {
+				return
+			}
+
+			count += replaced
+		}
+	}
+
+	count += rewriteOSC(c.EventStream, func(body string) string {
+		code, payload, ok := strings.Cut(body, ";")
+		if !ok {
+			return body
+		}
+
+		switch {
+		case (code == "0" || code == "1" || code == "2") && placeholders.Title != "":
+			payload = placeholders.Title
+		case code == "7" && strings.HasPrefix(payload, "file://"):
+			host, path := splitFileURI(strings.TrimPrefix(payload, "file://"))
+			if host != "" {
+				host = placeholders.Host
+			}
+
+			payload = "file://" + host + rules.apply(path)
+		default:
+			payload = rules.apply(payload)
+		}
+
+		return code + ";" + payload
+	})
+
+	for _, ev := range c.EventStream {
+		if ev.Type == "m" {
+			ev.Data = rules.apply(ev.Data)
+		}
+	}
+
+	anonymizeHeader(&c.Header, rules)
+
+	return
+}
+
+// anonymizeHeader drops the timestamp of a header and removes the
+// identity from its other fields.
+func anonymizeHeader(header *Header, rules anonymizeRuleSet) {
+	header.Timestamp = 0
+	header.Title = rules.apply(header.Title)
+	header.Command = rules.apply(header.Command)
+
+	var env map[string]string
+
+	for _, key := range []string{"TERM", "SHELL"} {
+		value, ok := header.Env[key]
+		if !ok {
+			continue
+		}
+
+		if env == nil {
+			env = make(map[string]string, 2)
+		}
+
+		env[key] = rules.apply(value)
+	}
+
+	header.Env = env
+
+	for idx, tag := range header.Tags {
+		header.Tags[idx] = rules.apply(tag)
+	}
+
+	header.Extra = anonymizeExtra(header.Extra, rules)
+	header.TermExtra = anonymizeExtra(header.TermExtra, rules)
+
+	if header.Theme != nil {
+		header.Theme.Extra = anonymizeExtra(header.Theme.Extra, rules)
+	}
+}
+
+// anonymizeExtra removes the identity from the strings within the
+// values of unmodeled keys, keeping the values that don't mention it
+// verbatim.
+func anonymizeExtra(extra map[string]json.RawMessage, rules anonymizeRuleSet) map[string]json.RawMessage {
+	if extra == nil {
+		return nil
+	}
+
+	res := make(map[string]json.RawMessage, len(extra))
+
+	for key, raw := range extra {
+		res[key] = raw
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+
+		var value interface{}
+		if decoder.Decode(&value) != nil {
+			continue
+		}
+
+		value, changed := anonymizeValue(value, rules)
+		if !changed {
+			continue
+		}
+
+		var buf bytes.Buffer
+
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+
+		if encoder.Encode(value) == nil {
+			res[key] = json.RawMessage(bytes.TrimSpace(buf.Bytes()))
+		}
+	}
+
+	return res
+}
+
+// anonymizeValue removes the identity from the strings within a decoded
+// JSON value, telling whether any of them changed.
+func anonymizeValue(value interface{}, rules anonymizeRuleSet) (interface{}, bool) {
+	changed := false
+
+	switch v := value.(type) {
+	case string:
+		res := rules.apply(v)
+		return res, res != v
+	case []interface{}:
+		for idx, item := range v {
+			var itemChanged bool
+			v[idx], itemChanged = anonymizeValue(item, rules)
+			changed = changed || itemChanged
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			var itemChanged bool
+			v[key], itemChanged = anonymizeValue(item, rules)
+			changed = changed || itemChanged
+		}
+	}
+
+	return value, changed
+}
+
+// anonymizeRule replaces the text matched by `pattern`.
+type anonymizeRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+type anonymizeRuleSet []anonymizeRule
+
+// anonymizeRules builds the rules replacing an identity: home
+// directories go first so that they're replaced as a whole, then host
+// names (along with their short form, e.g. `box` for `box.local`) and
+// finally user names, the longest first.
+func anonymizeRules(id Identity, placeholders Placeholders) (rules anonymizeRuleSet) {
+	var (
+		users = sortedByLength(id.Users)
+		hosts []string
+		seen  = map[string]bool{}
+	)
+
+	for _, user := range users {
+		seen[user] = true
+	}
+
+	for _, host := range id.Hosts {
+		short, _, _ := strings.Cut(host, ".")
+		for _, name := range []string{host, short} {
+			if !seen[name] {
+				seen[name] = true
+				hosts = append(hosts, name)
+			}
+		}
+	}
+
+	add := func(names []string, replacement string) {
+		for _, name := range sortedByLength(names) {
+			rules = append(rules, anonymizeRule{
+				pattern:     wordPattern(name),
+				replacement: strings.ReplaceAll(replacement, "$", "$$"),
+			})
+		}
+	}
+
+	add(id.Homes, placeholders.Home)
+	add(hosts, placeholders.Host)
+	add(users, placeholders.User)
+
+	return
+}
+
+// apply replaces the identity in `s`.
+func (rules anonymizeRuleSet) apply(s string) string {
+	for _, rule := range rules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+
+	return s
+}
+
+// wordPattern matches `s` literally, as long as it isn't part of a
+// longer word.
+func wordPattern(s string) *regexp.Regexp {
+	var (
+		expr     = regexp.QuoteMeta(s)
+		first, _ = utf8.DecodeRuneInString(s)
+		last, _  = utf8.DecodeLastRuneInString(s)
+	)
+
+	if isWordRune(first) {
+		expr = `\b` + expr
+	}
+
+	if isWordRune(last) {
+		expr += `\b`
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// sortedByLength returns the non-empty strings of `list`, the longest
+// first.
+func sortedByLength(list []string) (res []string) {
+	for _, s := range list {
+		if s != "" {
+			res = append(res, s)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return len(res[i]) > len(res[j])
+	})
+
+	return
+}
+
+// appendUnique appends `s` to `list` unless it's empty or already there.
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
+
+// splitFileURI splits what follows `file://` into a host and a path.
+func splitFileURI(uri string) (host, path string) {
+	idx := strings.IndexByte(uri, '/')
+	if idx < 0 {
+		return uri, ""
+	}
+
+	return uri[:idx], uri[idx:]
+}
+
+// rewriteOSC rewrites the bodies (e.g. `7;file://...`, without the
+// introducer and the terminator) of the OSC sequences in the output
+// events, returning the number of sequences that changed. Events are
+// left untouched if none did.
+//
+// A sequence split across events is rewritten as a whole: its body ends
+// up in the event it started in, which the terminal can't tell apart
+// as OSC sequences print nothing.
+func rewriteOSC(events []*Event, rewrite func(body string) string) (count int) {
+	var (
+		scanner textScanner
+		data    = make([][]byte, len(events))
+		start   = -1
+		body    []byte
+	)
+
+	flush := func() {
+		rewritten := rewrite(string(body))
+		if rewritten != string(body) {
+			count++
+		}
+
+		data[start] = append(data[start], rewritten...)
+		start, body = -1, nil
+	}
+
+	for idx, ev := range events {
+		if ev.Type != "o" {
+			continue
+		}
+
+		data[idx] = make([]byte, 0, len(ev.Data))
+
+		for offset, r := range ev.Data {
+			var (
+				_, size = utf8.DecodeRuneInString(ev.Data[offset:])
+				raw     = ev.Data[offset : offset+size]
+				prev    = scanner.state
+			)
+
+			scanner.printable(r)
+			inString := scanner.state == textString || scanner.state == textStringEscape
+
+			switch {
+			case start >= 0 && inString:
+				body = append(body, raw...)
+			case start >= 0:
+				if prev == textStringEscape {
+					// ST (`ESC \`), whose ESC went to the body.
+					body = body[:len(body)-1]
+					flush()
+					data[idx] = append(data[idx], 0x1b)
+				} else {
+					flush()
+				}
+
+				data[idx] = append(data[idx], raw...)
+			default:
+				data[idx] = append(data[idx], raw...)
+
+				if inString && ((prev == textEscape && r == ']') || (prev == textGround && r == 0x9d)) {
+					start = idx
+				}
+			}
+		}
+	}
+
+	if start >= 0 {
+		flush()
+	}
+
+	if count == 0 {
+		return
+	}
+
+	for idx, ev := range events {
+		if ev.Type == "o" {
+			ev.Data = string(data[idx])
+		}
+	}
+
+	return
+}
@@ -0,0 +1,161 @@
+package cast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestDetectIdentity(t *testing.T) {
+	t.Run("With nil cast", func(t *testing.T) {
+		_, err := cast.DetectIdentity(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Detects the identity from OSC 7 and the env", func(t *testing.T) {
+		c := &cast.Cast{
+			Header: cast.Header{
+				Env: map[string]string{"USER": "bob", "HOSTNAME": "ci"},
+			},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "\x1b]7;file://box.local/Users/alice/src\x07$ "},
+				{Time: cast.Seconds(2), Type: "o", Data: "\x1b]7;file://localhost/home/carol\x1b\\"},
+			},
+		}
+
+		id, err := cast.DetectIdentity(c)
+		assert.NoError(t, err)
+		assert.Equal(t, cast.Identity{
+			Users: []string{"bob", "alice", "carol"},
+			Hosts: []string{"ci", "box.local"},
+			Homes: []string{"/Users/alice", "/home/carol"},
+		}, id)
+	})
+}
+
+func TestAnonymize(t *testing.T) {
+	var (
+		id = cast.Identity{
+			Users: []string{"alice"},
+			Hosts: []string{"box.local"},
+			Homes: []string{"/Users/alice"},
+		}
+		placeholders = cast.DefaultPlaceholders()
+	)
+
+	t.Run("With nil cast", func(t *testing.T) {
+		_, err := cast.Anonymize(nil, id, placeholders)
+		assert.Error(t, err)
+	})
+
+	t.Run("With relative home placeholder", func(t *testing.T) {
+		_, err := cast.Anonymize(&cast.Cast{}, id, cast.Placeholders{
+			User: "user", Host: "host", Home: "home",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Replaces the identity in text", func(t *testing.T) {
+		c := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "alice@box:/Users/al"},
+				{Time: cast.Seconds(2), Type: "o", Data: "ice/src$ "},
+				{Time: cast.Seconds(3), Type: "i", Data: "ssh alice@box.local\r"},
+				{Time: cast.Seconds(4), Type: "m", Data: "alice's talk"},
+				{Time: cast.Seconds(5), Type: "o", Data: "malice\r\n"},
+			},
+		}
+
+		_, err := cast.Anonymize(c, id, placeholders)
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "user@host:/home/u"},
+			{Time: cast.Seconds(2), Type: "o", Data: "ser/src$ "},
+			{Time: cast.Seconds(3), Type: "i", Data: "ssh user@host\r"},
+			{Time: cast.Seconds(4), Type: "m", Data: "user's talk"},
+			{Time: cast.Seconds(5), Type: "o", Data: "malice\r\n"},
+		}, c.EventStream)
+	})
+
+	t.Run("Rewrites OSC sequences", func(t *testing.T) {
+		c := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "\x1b]7;file://box.local/Users/alice/src\x07"},
+				{Time: cast.Seconds(2), Type: "o", Data: "\x1b]2;alice@box: ~\x1b"},
+				{Time: cast.Seconds(3), Type: "o", Data: "\\$ "},
+				{Time: cast.Seconds(4), Type: "o", Data: "\x1b]7;file://other/Users/al"},
+				{Time: cast.Seconds(5), Type: "o", Data: "ice\x07"},
+			},
+		}
+
+		count, err := cast.Anonymize(c, id, placeholders)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "\x1b]7;file://host/home/user/src\x07"},
+			{Time: cast.Seconds(2), Type: "o", Data: "\x1b]2;user@host: ~"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\x1b\\$ "},
+			{Time: cast.Seconds(4), Type: "o", Data: "\x1b]7;file://host/home/user"},
+			{Time: cast.Seconds(5), Type: "o", Data: "\x07"},
+		}, c.EventStream)
+	})
+
+	t.Run("Replaces window titles as a whole", func(t *testing.T) {
+		c := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "\x1b]0;vim ~/notes\x07"},
+			},
+		}
+
+		_, err := cast.Anonymize(c, id, cast.Placeholders{
+			User: "user", Host: "host", Home: "/home/user", Title: "demo",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "\x1b]0;demo\x07", c.EventStream[0].Data)
+	})
+
+	t.Run("Normalizes the header", func(t *testing.T) {
+		c := &cast.Cast{
+			Header: cast.Header{
+				Version:   2,
+				Timestamp: 1530639832,
+				Title:     "alice's demo",
+				Env: map[string]string{
+					"TERM":  "xterm-256color",
+					"SHELL": "/Users/alice/bin/zsh",
+					"USER":  "alice",
+				},
+			},
+		}
+
+		_, err := cast.Anonymize(c, id, placeholders)
+		assert.NoError(t, err)
+		assert.Equal(t, cast.Header{
+			Version: 2,
+			Title:   "user's demo",
+			Env: map[string]string{
+				"TERM":  "xterm-256color",
+				"SHELL": "/home/user/bin/zsh",
+			},
+		}, c.Header)
+	})
+
+	t.Run("Replaces the identity in tags and unknown keys", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(`{"version": 3, "term": {"cols": 80, "rows": 24, ` +
+			`"theme": {"fg": "#fff", "owner": "alice"}, "host": "box.local"}, "tags": ["alice", "demo"], ` +
+			`"author": {"name": "alice", "id": 42}, "license": "MIT <x>"}`))
+		assert.NoError(t, err)
+
+		_, err = cast.Anonymize(c, id, placeholders)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"user", "demo"}, c.Header.Tags)
+		assert.Equal(t, `{"id":42,"name":"user"}`, string(c.Header.Extra["author"]))
+		assert.Equal(t, `"MIT <x>"`, string(c.Header.Extra["license"]))
+		assert.Equal(t, `"host"`, string(c.Header.TermExtra["host"]))
+		assert.Equal(t, `"user"`, string(c.Header.Theme.Extra["owner"]))
+	})
+}
@@ -10,12 +10,19 @@
 //   - all following lines form an event stream, each line representing a separate
 //     event, encoded as 3-element JSON array.
 //
+// Casts in the older v1 format (a single JSON document) can be decoded
+// as well, being converted to the v2 model on the fly. Casts in the v3
+// format, whose event times are intervals relative to the previous
+// event, are both decoded and encoded (see `Header.Version`).
+//
 // [1]: https://github.com/asciinema/asciinema/blob/49a892d9e6f57ab3a774c0835fa563c77cf6a7a7/doc/asciicast-v2.md.
 package cast
 
 import (
 	"encoding/json"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -24,7 +31,9 @@ import (
 // recording meta-data.
 type Header struct {
 	// Version represents the version of the current ascii cast format
-	// (must be `2`).
+	// (must be `2` or `3`).
+	//
+	// It determines the format the cast gets encoded with.
 	//
 	// This field is required for a valid header.
 	Version uint8 `json:"version"`
@@ -48,51 +57,76 @@ type Header struct {
 	Command string `json:"command,omitempty"`
 
 	// Theme describes the color theme of the recorded terminal.
-	// Theme struct {
-	// 	// Fg corresponds to the normal text color (foreground).
-	// 	Fg string `json:"fg,omitempty"`
-
-	// 	// Bg corresponds to the normal background color.
-	// 	Bg string `json:"bg,omitempty"`
+	Theme *Theme `json:"theme,omitempty"`
 
-	// 	// Palette specifies a list of 8 or 16 colors separated by
-	// 	// colon character to apply a theme to the session
-	// 	Palette string `json:"palette,omitempty"`
-	// } `json:"theme,omitempty"`
-
-	// // Title corresponds to the title of the cast.
+	// Title corresponds to the title of the cast.
 	Title string `json:"title,omitempty"`
 
-	// // IdleTimeLimit specifies the maximum amount of idleness between
+	// IdleTimeLimit specifies the maximum amount of idleness between
 	// one command and another.
 	IdleTimeLimit float64 `json:"idle_time_limit,omitempty"`
 
 	// Env specifies a map of environment variables captured by the
 	// asciinema command.
 	//
-	// ps.: the official asciinema client only captures `SHELL` and `TERM`.
-	Env struct {
-		// Shell corresponds to the captured SHELL environment variable.
-		Shell string `json:"SHELL,omitempty"`
-
-		// Term corresponds to the captured TERM environment variable.
-		Term string `json:"TERM,omitempty"`
-	} `json:"env,omitempty"`
+	// ps.: the official asciinema client only captures `SHELL` and `TERM`
+	// by default.
+	Env map[string]string `json:"env,omitempty"`
+
+	// TermVersion is the version of the terminal emulator used for
+	// the recording.
+	//
+	// ps.: v2 has no such field; v2 casts keep it under `term`.
+	TermVersion string `json:"-"`
+
+	// Tags is a list of tags that categorize the recording.
+	//
+	// ps.: v2 has no such field; v2 casts keep it under `tags`.
+	Tags []string `json:"-"`
+
+	// TermExtra holds the keys of the v3 `term` object that aren't
+	// modeled by this struct, keeping their values verbatim.
+	//
+	// ps.: v2 has no such object; v2 casts keep them under `term`.
+	TermExtra map[string]json.RawMessage `json:"-"`
+
+	// Extra holds the header keys that aren't modeled by this struct,
+	// keeping their values verbatim so that they survive a
+	// decode-encode round trip.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Theme describes the color theme of the recorded terminal.
+type Theme struct {
+	// Fg corresponds to the normal text color (foreground).
+	Fg string `json:"fg,omitempty"`
+
+	// Bg corresponds to the normal background color.
+	Bg string `json:"bg,omitempty"`
+
+	// Palette specifies a list of 8 or 16 colors separated by
+	// colon character to apply a theme to the session
+	Palette string `json:"palette,omitempty"`
+
+	// Extra holds the theme keys that aren't modeled by this struct,
+	// keeping their values verbatim.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Event represents terminal inputs that get recorded by asciinema.
 type Event struct {
-	// Time indicates when this event happened, represented as the number
-	// of seconds since the beginning of the recording session.
-	Time float64
+	// Time indicates when this event happened, counted since the
+	// beginning of the recording session.
+	Time Time
 
 	// Type represents the type of the data that's been recorded.
 	//
-	// Four types are possible:
-	//   - "o": data written to stdout; and
-	//   - "i": data read from stdin.
-	//   - "r": change window size
-	//   - "m": marker
+	// Five types are possible:
+	//   - "o": data written to stdout;
+	//   - "i": data read from stdin;
+	//   - "r": change window size;
+	//   - "m": marker; and
+	//   - "x": exit status of the recorded process (v3).
 	Type string
 
 	// Data represents the data recorded from the terminal.
@@ -110,11 +144,11 @@ type Cast struct {
 }
 
 // ValidateHeader verifies whether the provided `cast` header structure is valid
-// or not based on the asciinema cast v2 protocol.
+// or not based on the asciinema cast v2 (or v3) protocol.
 func (header *Header) ValidateHeader() error {
 
-	if header.Version != 2 {
-		return errors.Errorf("only casts with version 2 are valid")
+	if header.Version != 2 && header.Version != 3 {
+		return errors.Errorf("only casts with version 2 or 3 are valid")
 	}
 
 	if header.Width == 0 {
@@ -128,10 +162,12 @@ func (header *Header) ValidateHeader() error {
 	return nil
 }
 
+// Encode writes the header using the format of its version.
 func (header *Header) Encode(e *json.Encoder) error {
 	if e == nil {
 		return errors.Errorf("encoder must not be nil")
 	}
+
 	return e.Encode(header)
 }
 
@@ -142,10 +178,10 @@ func (event *Event) ValidateEvent() error {
 	}
 
 	switch event.Type {
-	case "i", "o", "r", "m":
+	case "i", "o", "r", "m", "x":
 		return nil
 	default:
-		return errors.Errorf("type must either be 'o', 'i', 'r', or 'm'")
+		return errors.Errorf("type must either be 'o', 'i', 'r', 'm' or 'x'")
 	}
 }
 
@@ -156,6 +192,29 @@ func (ev *Event) Encode(e *json.Encoder) error {
 	return e.Encode([]interface{}{ev.Time, ev.Type, ev.Data})
 }
 
+// ParseSize parses the data of a resize event (`COLSxROWS`).
+func ParseSize(data string) (width, height int, err error) {
+	parts := strings.Split(data, "x")
+	if len(parts) != 2 {
+		err = errors.Errorf("malformed size %q", data)
+		return
+	}
+
+	width, err = strconv.Atoi(parts[0])
+	if err != nil || width < 1 {
+		err = errors.Errorf("malformed width in %q", data)
+		return
+	}
+
+	height, err = strconv.Atoi(parts[1])
+	if err != nil || height < 1 {
+		err = errors.Errorf("malformed height in %q", data)
+		return
+	}
+
+	return
+}
+
 // ValidateEventStream makes sure that a given set of events (event stream)
 // is valid.
 //
@@ -163,7 +222,7 @@ func (ev *Event) Encode(e *json.Encoder) error {
 // - be ordered by time; and
 // - have valid events.
 func ValidateEventStream(eventStream []*Event) error {
-	var lastTime float64
+	var lastTime Time
 
 	for _, ev := range eventStream {
 		if ev.Time < lastTime {
@@ -198,6 +257,8 @@ func (cast *Cast) Validate() error {
 
 // Encode writes the encoding of `Cast` into the writer passed as an argument.
 //
+// The format (v2 or v3) is determined by `Header.Version`.
+//
 // ps.: this method **will not** validate whether the cast is a valid V2
 // cast or not. Make sure you call `Validate` before.
 func (cast *Cast) Encode(writer io.Writer) error {
@@ -209,16 +270,14 @@ func (cast *Cast) Encode(writer io.Writer) error {
 		return errors.New("a cast must be specified")
 	}
 
-	encoder := json.NewEncoder(writer)
-	encoder.SetIndent("", "")
-
-	if err := cast.Header.Encode(encoder); err != nil {
-		return errors.Wrap(err, "failed to encode header")
+	w, err := NewWriter(writer, &cast.Header)
+	if err != nil {
+		return err
 	}
 
 	for _, ev := range cast.EventStream {
-		if err := ev.Encode(encoder); err != nil {
-			return errors.Wrap(err, "failed to encode event")
+		if err := w.WriteEvent(ev); err != nil {
+			return err
 		}
 	}
 
@@ -229,70 +288,26 @@ func (cast *Cast) Encode(writer io.Writer) error {
 // whether the stream contains a valid asciinema cast and then unmarshals it
 // into a cast struct.
 func Decode(reader io.Reader) (*Cast, error) {
+	r, err := NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
 
 	cast := &Cast{
+		Header:      *r.Header(),
 		EventStream: make([]*Event, 0),
-		Header: Header{
-			Version: 2,
-		},
-	}
-
-	if reader == nil {
-		return nil, errors.New("a reader must be specified")
 	}
 
-	decoder := json.NewDecoder(reader)
-	decoder.DisallowUnknownFields()
-
-	err := decoder.Decode(&cast.Header)
-	if err != nil {
-		return nil, errors.Wrapf(err,
-			"couldn't decode header")
-	}
-
-	var (
-		ev     = new([3]interface{})
-		ok     bool
-		time   float64
-		evType string
-		data   string
-	)
-
 	for {
-		err = decoder.Decode(ev)
+		ev, err := r.NextEvent()
 		if err != nil {
 			if err == io.EOF {
 				return cast, nil
 			}
 
-			return nil, errors.Wrapf(err,
-				"failed to parse ev line")
+			return nil, err
 		}
 
-		time, ok = ev[0].(float64)
-		if !ok {
-			return nil, errors.Errorf("first element of event is not a float64")
-		}
-
-		evType, ok = ev[1].(string)
-		if !ok {
-			return nil, errors.Errorf("second element of event is not a string")
-		}
-
-		data, ok = ev[2].(string)
-		if !ok {
-			return nil, errors.Errorf("third element of event is not a string")
-		}
-		ev := &Event{
-			Time: time,
-			Type: evType,
-			Data: data,
-		}
-		if err = ev.ValidateEvent(); err != nil {
-			return nil, errors.Wrapf(err,
-				"invalid event")
-		}
 		cast.EventStream = append(cast.EventStream, ev)
 	}
-
 }
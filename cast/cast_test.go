@@ -15,6 +15,12 @@ func TestValidVersion2Header(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidVersion3Header(t *testing.T) {
+	header := Header{Version: 3, Width: 80, Height: 24}
+	err := header.ValidateHeader()
+	assert.NoError(t, err)
+}
+
 func TestInvalidVersionHeader(t *testing.T) {
 	header := Header{Version: 1, Width: 80, Height: 24}
 	err := header.ValidateHeader()
@@ -87,8 +93,8 @@ func TestCast_Encode(t *testing.T) {
 	cast := Cast{
 		Header: Header{Version: 2, Width: 80, Height: 24},
 		EventStream: []*Event{
-			{Time: 1.0, Type: "o", Data: "data1"},
-			{Time: 2.0, Type: "r", Data: "10x20"},
+			{Time: Seconds(1.0), Type: "o", Data: "data1"},
+			{Time: Seconds(2.0), Type: "r", Data: "10x20"},
 		},
 	}
 
@@ -140,8 +146,8 @@ func TestCast_Decode(t *testing.T) {
 
 	// Test case with valid header and events
 	header = &Header{Version: 2, Width: 80, Height: 24}
-	event1 := &Event{Time: 1.0, Type: "o", Data: "data1"}
-	event2 := &Event{Time: 2.0, Type: "r", Data: "10x20"}
+	event1 := &Event{Time: Seconds(1.0), Type: "o", Data: "data1"}
+	event2 := &Event{Time: Seconds(2.0), Type: "r", Data: "10x20"}
 
 	writer.Reset()
 	e = json.NewEncoder(writer)
@@ -174,7 +180,7 @@ func TestHeader_Encode(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Validate encoded output
-	expected := []byte("{\"version\":2,\"width\":80,\"height\":24,\"env\":{}}\n")
+	expected := []byte("{\"version\":2,\"width\":80,\"height\":24}\n")
 	assert.Equal(t, expected, writer.Bytes())
 
 }
@@ -187,7 +193,7 @@ func TestEvent_Encode(t *testing.T) {
 	assert.Error(t, err)
 
 	// Test case with valid event
-	event = Event{Time: 1.0, Type: "o", Data: "data"}
+	event = Event{Time: Seconds(1.0), Type: "o", Data: "data"}
 	writer := &bytes.Buffer{}
 	e := json.NewEncoder(writer)
 
@@ -195,7 +201,19 @@ func TestEvent_Encode(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Validate encoded output
-	expected := []byte("[1,\"o\",\"data\"]\n")
+	expected := []byte("[1.000000,\"o\",\"data\"]\n")
 	assert.Equal(t, expected, writer.Bytes())
 
 }
+
+func TestParseSize(t *testing.T) {
+	width, height, err := ParseSize("120x40")
+	assert.NoError(t, err)
+	assert.Equal(t, 120, width)
+	assert.Equal(t, 40, height)
+
+	for _, input := range []string{"", "120", "0x40", "ax40", "120x", "1x2x3"} {
+		_, _, err := ParseSize(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,110 @@
+package cast
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ConcatOptions tunes how `Concat` joins casts.
+type ConcatOptions struct {
+	// Gap is the time between the last event of a cast and the
+	// beginning of the next one.
+	Gap Time
+
+	// Markers adds a marker (`m`) event at the beginning of every cast
+	// but the first, labeled with the matching entry of `Labels` or
+	// with `part N` if there's none.
+	Markers bool
+	Labels  []string
+}
+
+// Concat joins casts one after the other into a new cast, leaving the
+// given ones untouched.
+//
+// The resulting cast takes the header of the first one. Whenever a cast
+// starts with a terminal size other than the one the previous cast left
+// the terminal with, a resize (`r`) event is inserted so that it still
+// renders at its own size. Exit status (`x`) events are only kept for
+// the last cast.
+func Concat(casts []*Cast, opts ConcatOptions) (*Cast, error) {
+	if len(casts) == 0 {
+		return nil, errors.Errorf("at least one cast must be specified")
+	}
+
+	for idx, c := range casts {
+		if c == nil {
+			return nil, errors.Errorf("cast %d must not be nil", idx+1)
+		}
+	}
+
+	if opts.Gap < 0 {
+		return nil, errors.Errorf("gap must not be negative")
+	}
+
+	var (
+		res = &Cast{
+			Header:      casts[0].Header,
+			EventStream: make([]*Event, 0),
+		}
+		width, height = casts[0].Header.Width, casts[0].Header.Height
+		offset, end   Time
+	)
+
+	for idx, c := range casts {
+		if idx > 0 {
+			offset = end + opts.Gap
+
+			if c.Header.Width != width || c.Header.Height != height {
+				width, height = c.Header.Width, c.Header.Height
+				res.EventStream = append(res.EventStream, &Event{
+					Time: offset,
+					Type: "r",
+					Data: fmt.Sprintf("%dx%d", width, height),
+				})
+			}
+
+			if opts.Markers {
+				res.EventStream = append(res.EventStream, &Event{
+					Time: offset,
+					Type: "m",
+					Data: concatLabel(opts.Labels, idx),
+				})
+			}
+		}
+
+		end = offset
+
+		for _, ev := range c.EventStream {
+			end = ev.Time + offset
+
+			if ev.Type == "x" && idx < len(casts)-1 {
+				continue
+			}
+
+			if ev.Type == "r" {
+				w, h, err := ParseSize(ev.Data)
+				if err == nil {
+					width, height = uint(w), uint(h)
+				}
+			}
+
+			res.EventStream = append(res.EventStream, &Event{
+				Time: end,
+				Type: ev.Type,
+				Data: ev.Data,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+// concatLabel is the label of the marker added before the cast `idx`.
+func concatLabel(labels []string, idx int) string {
+	if idx < len(labels) && labels[idx] != "" {
+		return labels[idx]
+	}
+
+	return fmt.Sprintf("part %d", idx+1)
+}
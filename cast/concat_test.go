@@ -0,0 +1,84 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestConcat(t *testing.T) {
+	var (
+		first = &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 80, Height: 24, Title: "first"},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(0.5), Type: "o", Data: "a"},
+				{Time: cast.Seconds(1.5), Type: "o", Data: "b"},
+				{Time: cast.Seconds(2), Type: "x", Data: "0"},
+			},
+		}
+		second = &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 80, Height: 24},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(0.25), Type: "o", Data: "c"},
+				{Time: cast.Seconds(1), Type: "r", Data: "100x30"},
+			},
+		}
+		third = &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 80, Height: 24},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(0.1), Type: "o", Data: "d"},
+				{Time: cast.Seconds(0.2), Type: "x", Data: "1"},
+			},
+		}
+	)
+
+	t.Run("Without casts", func(t *testing.T) {
+		_, err := cast.Concat(nil, cast.ConcatOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("With nil cast", func(t *testing.T) {
+		_, err := cast.Concat([]*cast.Cast{first, nil}, cast.ConcatOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("With negative gap", func(t *testing.T) {
+		_, err := cast.Concat([]*cast.Cast{first}, cast.ConcatOptions{Gap: cast.Seconds(-1)})
+		assert.Error(t, err)
+	})
+
+	t.Run("Joins casts with a gap", func(t *testing.T) {
+		res, err := cast.Concat([]*cast.Cast{first, second}, cast.ConcatOptions{Gap: cast.Seconds(1)})
+		assert.NoError(t, err)
+		assert.Equal(t, first.Header, res.Header)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0.5), Type: "o", Data: "a"},
+			{Time: cast.Seconds(1.5), Type: "o", Data: "b"},
+			{Time: cast.Seconds(3.25), Type: "o", Data: "c"},
+			{Time: cast.Seconds(4), Type: "r", Data: "100x30"},
+		}, res.EventStream)
+
+		assert.Equal(t, cast.Seconds(0.25), second.EventStream[0].Time)
+	})
+
+	t.Run("Restores sizes and adds markers", func(t *testing.T) {
+		res, err := cast.Concat([]*cast.Cast{first, second, third}, cast.ConcatOptions{
+			Markers: true,
+			Labels:  []string{"first", "", "third"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0.5), Type: "o", Data: "a"},
+			{Time: cast.Seconds(1.5), Type: "o", Data: "b"},
+			{Time: cast.Seconds(2), Type: "m", Data: "part 2"},
+			{Time: cast.Seconds(2.25), Type: "o", Data: "c"},
+			{Time: cast.Seconds(3), Type: "r", Data: "100x30"},
+			{Time: cast.Seconds(3), Type: "r", Data: "80x24"},
+			{Time: cast.Seconds(3), Type: "m", Data: "third"},
+			{Time: cast.Seconds(3.1), Type: "o", Data: "d"},
+			{Time: cast.Seconds(3.2), Type: "x", Data: "1"},
+		}, res.EventStream)
+	})
+}
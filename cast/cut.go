@@ -1,8 +1,6 @@
 package cast
 
 import (
-	"math"
-
 	"github.com/pkg/errors"
 )
 
@@ -24,55 +22,134 @@ import (
 // 2. search a time that is close to `to`; then
 // 3. remove all in between; then
 // 4. adjust the time of the remaining.
-func Cut(c *Cast, from, to float64) error {
-	if c == nil {
-		return errors.Errorf("a cast must be specified")
+func Cut(c *Cast, from, to Time) error {
+	if from > to {
+		return errors.Errorf(
+			"`from` cant be bigger than `to`")
 	}
 
-	if len(c.EventStream) == 0 {
-		return errors.Errorf(
-			"a cast with non-empty event stream must be supplied")
+	return CutRange(c, Range{From: TimeAt(from), To: TimeAt(to)}, SnapExact)
+}
+
+// CutRange removes the piece of the cast event stream delimited by
+// `r` (both ends included), snapping times to events according to
+// `policy` (see `ResolveRange`).
+func CutRange(c *Cast, r Range, policy SnapPolicy) error {
+	return CutRanges(c, []Range{r}, policy)
+}
+
+// CutRanges removes several pieces of the cast event stream at once
+// (see `CutRange`). The ranges all refer to the original timeline, as
+// if they were removed at the same time, and must not overlap.
+func CutRanges(c *Cast, ranges []Range, policy SnapPolicy) error {
+	resolved, err := resolveRanges(c, ranges, policy, false)
+	if err != nil {
+		return err
 	}
 
-	if from > to {
-		return errors.Errorf(
-			"`from` cant be bigger than `to`")
+	for _, r := range resolved {
+		cutEvents(c, r.fromIdx, r.toIdx)
+	}
+
+	return nil
+}
+
+// CutRangePreserving removes the piece of the cast event stream
+// delimited by `r` just like `CutRange`, while keeping what it changed
+// of the state of the player: the last resize (`r`) event and the
+// markers (`m`) of the piece are moved to where the cut happens, along
+// with the events that `restore` returns (unless it's nil) to redraw the
+// screen as it was at the end of the piece.
+func CutRangePreserving(c *Cast, r Range, policy SnapPolicy, restore Restorer) error {
+	return CutRangesPreserving(c, []Range{r}, policy, restore)
+}
+
+// CutRangesPreserving removes several pieces of the cast event stream at
+// once like `CutRanges`, keeping what each of them changed of the state
+// of the player (see `CutRangePreserving`).
+func CutRangesPreserving(c *Cast, ranges []Range, policy SnapPolicy, restore Restorer) error {
+	resolved, err := resolveRanges(c, ranges, policy, false)
+	if err != nil {
+		return err
 	}
 
+	for _, r := range resolved {
+		err = cutPreserving(c, r.fromIdx, r.toIdx, restore)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cutPreserving removes the events from `fromIdx` to `toIdx` (both
+// included), keeping what they changed of the state of the player.
+func cutPreserving(c *Cast, fromIdx, toIdx int, restore Restorer) (err error) {
 	var (
-		fromIdx = -1
-		toIdx   = -1
+		at      = c.EventStream[fromIdx].Time
+		resize  *Event
+		markers []*Event
+		drawn   bool
+		kept    []*Event
 	)
 
-	for idx, ev := range c.EventStream {
-		if ev.Time == from {
-			fromIdx = idx
+	// kept events get times relative to the cut until it's done.
+	for _, ev := range c.EventStream[fromIdx : toIdx+1] {
+		switch ev.Type {
+		case "r":
+			resize = &Event{Type: ev.Type, Data: ev.Data}
+			drawn = true
+		case "m":
+			markers = append(markers, &Event{Type: ev.Type, Data: ev.Data})
+		case "o":
+			drawn = true
 		}
+	}
 
-		if ev.Time == to {
-			toIdx = idx
-		}
+	if resize != nil {
+		kept = append(kept, resize)
 	}
 
-	if fromIdx == -1 {
-		return errors.Errorf("couldn't find initial frame")
+	kept = append(kept, markers...)
+
+	if restore != nil && drawn {
+		var events []*Event
+
+		events, err = restore(c.Header, c.EventStream[:toIdx+1])
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore the state at %v", c.EventStream[toIdx].Time)
+		}
+
+		kept = append(kept, events...)
 	}
 
-	if toIdx == -1 {
-		return errors.Errorf("couldn't find final frame")
+	cutEvents(c, fromIdx, toIdx)
+
+	for _, ev := range kept {
+		ev.Time += at
 	}
 
+	events := make([]*Event, 0, len(c.EventStream)+len(kept))
+	events = append(events, c.EventStream[:fromIdx]...)
+	events = append(events, kept...)
+	events = append(events, c.EventStream[fromIdx:]...)
+	c.EventStream = events
+
+	return nil
+}
+
+// cutEvents removes the events from `fromIdx` to `toIdx` (both included)
+// and brings the remaining ones closer so that no gap is left behind.
+func cutEvents(c *Cast, fromIdx, toIdx int) {
 	if toIdx+1 < len(c.EventStream) {
 		delta := c.EventStream[toIdx+1].Time - c.EventStream[fromIdx].Time
 		for _, remainingElem := range c.EventStream[toIdx+1:] {
 			remainingElem.Time -= delta
-			remainingElem.Time = math.Round(remainingElem.Time*1000) / 1000
 		}
 	}
 
 	c.EventStream = append(
 		c.EventStream[:fromIdx],
 		c.EventStream[toIdx+1:]...)
-
-	return nil
 }
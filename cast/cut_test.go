@@ -14,17 +14,17 @@ func TestCut_Validation(t *testing.T) {
 	}
 
 	t.Run("With nil cast", func(t *testing.T) {
-		err := cast.Cut(nil, 1, 2)
+		err := cast.Cut(nil, cast.Seconds(1), cast.Seconds(2))
 		assert.Error(t, err)
 	})
 
 	t.Run("With an empty event stream", func(t *testing.T) {
-		err := cast.Cut(data, 1, 2)
+		err := cast.Cut(data, cast.Seconds(1), cast.Seconds(2))
 		assert.Error(t, err)
 	})
 
 	t.Run("With `from` > `to`", func(t *testing.T) {
-		err := cast.Cut(data, 3, 2)
+		err := cast.Cut(data, cast.Seconds(3), cast.Seconds(2))
 		assert.Error(t, err)
 	})
 }
@@ -40,19 +40,19 @@ func TestCut_Stream(t *testing.T) {
 
 		setup := func() {
 			event1 = &cast.Event{
-				Time: 1,
+				Time: cast.Seconds(1),
 				Data: "event1",
 			}
 			event1_2 = &cast.Event{
-				Time: 1.2,
+				Time: cast.Seconds(1.2),
 				Data: "event1_2",
 			}
 			event1_6 = &cast.Event{
-				Time: 1.6,
+				Time: cast.Seconds(1.6),
 				Data: "event1_6",
 			}
 			event2 = &cast.Event{
-				Time: 2,
+				Time: cast.Seconds(2),
 				Data: "event2",
 			}
 
@@ -70,19 +70,19 @@ func TestCut_Stream(t *testing.T) {
 
 		t.Run("With `from` not found", func(t *testing.T) {
 			setup()
-			err = cast.Cut(data, 1.1, 2)
+			err = cast.Cut(data, cast.Seconds(1.1), cast.Seconds(2))
 			assert.Error(t, err)
 		})
 
 		t.Run("With `to` not found", func(t *testing.T) {
 			setup()
-			err = cast.Cut(data, 2, 3.3)
+			err = cast.Cut(data, cast.Seconds(2), cast.Seconds(3.3))
 			assert.Error(t, err)
 		})
 
 		t.Run("Cutting a single frame when `from` == `to`", func(t *testing.T) {
 			setup()
-			err = cast.Cut(data, 1.2, 1.2)
+			err = cast.Cut(data, cast.Seconds(1.2), cast.Seconds(1.2))
 			assert.NoError(t, err)
 
 			assert.Contains(t, data.EventStream, event1)
@@ -92,14 +92,14 @@ func TestCut_Stream(t *testing.T) {
 
 			assert.Len(t, data.EventStream, initialNumberOfEvents-1)
 
-			assert.Equal(t, float64(1), event1.Time)
-			assert.Equal(t, float64(1.2), event1_6.Time)
-			assert.Equal(t, float64(1.6), event2.Time)
+			assert.Equal(t, cast.Seconds(1), event1.Time)
+			assert.Equal(t, cast.Seconds(1.2), event1_6.Time)
+			assert.Equal(t, cast.Seconds(1.6), event2.Time)
 		})
 
 		t.Run("Cutting range without bounds included", func(t *testing.T) {
 			setup()
-			err = cast.Cut(data, 1.2, 1.6)
+			err = cast.Cut(data, cast.Seconds(1.2), cast.Seconds(1.6))
 			assert.NoError(t, err)
 
 			assert.Contains(t, data.EventStream, event1)
@@ -109,13 +109,13 @@ func TestCut_Stream(t *testing.T) {
 
 			assert.Len(t, data.EventStream, initialNumberOfEvents-2)
 
-			assert.Equal(t, float64(1), event1.Time)
-			assert.Equal(t, float64(1.2), event2.Time)
+			assert.Equal(t, cast.Seconds(1), event1.Time)
+			assert.Equal(t, cast.Seconds(1.2), event2.Time)
 		})
 
 		t.Run("Cuts frames in range containing last element", func(t *testing.T) {
 			setup()
-			err = cast.Cut(data, 1.2, 2)
+			err = cast.Cut(data, cast.Seconds(1.2), cast.Seconds(2))
 			assert.NoError(t, err)
 
 			assert.Contains(t, data.EventStream, event1)
@@ -127,3 +127,90 @@ func TestCut_Stream(t *testing.T) {
 		})
 	})
 }
+
+func TestCutRangePreserving(t *testing.T) {
+	var (
+		newCast = func() *cast.Cast {
+			return &cast.Cast{
+				Header: cast.Header{Version: 2, Width: 80, Height: 24},
+				EventStream: []*cast.Event{
+					{Time: cast.Seconds(1), Type: "o", Data: "a"},
+					{Time: cast.Seconds(2), Type: "r", Data: "100x30"},
+					{Time: cast.Seconds(3), Type: "m", Data: "step"},
+					{Time: cast.Seconds(4), Type: "r", Data: "120x40"},
+					{Time: cast.Seconds(5), Type: "o", Data: "b"},
+					{Time: cast.Seconds(6), Type: "i", Data: "c"},
+					{Time: cast.Seconds(7), Type: "o", Data: "d"},
+				},
+			}
+		}
+		span = cast.Range{From: cast.TimeAt(cast.Seconds(2)), To: cast.TimeAt(cast.Seconds(5))}
+	)
+
+	t.Run("Moves resizes and markers to the cut", func(t *testing.T) {
+		c := newCast()
+
+		err := cast.CutRangePreserving(c, span, cast.SnapExact, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(2), Type: "r", Data: "120x40"},
+			{Time: cast.Seconds(2), Type: "m", Data: "step"},
+			{Time: cast.Seconds(2), Type: "i", Data: "c"},
+			{Time: cast.Seconds(3), Type: "o", Data: "d"},
+		}, c.EventStream)
+	})
+
+	t.Run("Redraws the screen as it was at the end of the cut", func(t *testing.T) {
+		var (
+			c      = newCast()
+			before []*cast.Event
+		)
+
+		err := cast.CutRangePreserving(c, span, cast.SnapExact,
+			func(header cast.Header, events []*cast.Event) ([]*cast.Event, error) {
+				before = events
+				return []*cast.Event{{Time: cast.Seconds(0), Type: "o", Data: "ab"}}, nil
+			})
+		assert.NoError(t, err)
+		assert.Len(t, before, 5)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(2), Type: "r", Data: "120x40"},
+			{Time: cast.Seconds(2), Type: "m", Data: "step"},
+			{Time: cast.Seconds(2), Type: "o", Data: "ab"},
+			{Time: cast.Seconds(2), Type: "i", Data: "c"},
+			{Time: cast.Seconds(3), Type: "o", Data: "d"},
+		}, c.EventStream)
+	})
+
+	t.Run("Doesn't redraw when nothing was drawn", func(t *testing.T) {
+		c := newCast()
+
+		err := cast.CutRangePreserving(c, cast.Range{From: cast.TimeAt(cast.Seconds(6)), To: cast.TimeAt(cast.Seconds(6))}, cast.SnapExact,
+			func(cast.Header, []*cast.Event) ([]*cast.Event, error) {
+				t.Fatal("unexpected redraw")
+				return nil, nil
+			})
+		assert.NoError(t, err)
+		assert.Len(t, c.EventStream, 6)
+	})
+
+	t.Run("Restores the original state at the end of each range", func(t *testing.T) {
+		var (
+			c       = newCast()
+			lengths []int
+		)
+
+		err := cast.CutRangesPreserving(c, []cast.Range{
+			{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(1))},
+			{From: cast.TimeAt(cast.Seconds(5)), To: cast.TimeAt(cast.Seconds(5))},
+		}, cast.SnapExact, func(header cast.Header, events []*cast.Event) ([]*cast.Event, error) {
+			lengths = append(lengths, len(events))
+			return nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{5, 1}, lengths)
+		assert.Len(t, c.EventStream, 5)
+	})
+}
@@ -0,0 +1,68 @@
+package cast
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Restorer returns the events that bring a player from the beginning of
+// a cast (as declared in `header`) to the state it's in once `events`
+// have been played, such as an output event redrawing the screen (see
+// `vt.Restore`).
+type Restorer func(header Header, events []*Event) ([]*Event, error)
+
+// Extract keeps the events from `from` to `to` (both included), see
+// `ExtractRange`.
+func Extract(c *Cast, from, to Time, restore Restorer) error {
+	if from > to {
+		return errors.Errorf("`from` cant be bigger than `to`")
+	}
+
+	return ExtractRange(c, Range{From: TimeAt(from), To: TimeAt(to)}, SnapExact, restore)
+}
+
+// ExtractRange keeps only the events delimited by `r` (both ends
+// included), snapping times to events according to `policy` (see
+// `ResolveRange`), and rebases their times so that the cast starts at
+// zero.
+//
+// The header gets the terminal size at the beginning of the range, and
+// its timestamp is moved forward accordingly. Unless `restore` is nil,
+// the events it returns for the events that got dropped from the
+// beginning of the cast are placed at time zero, so that the cast starts
+// with whatever was on the screen at that point.
+func ExtractRange(c *Cast, r Range, policy SnapPolicy, restore Restorer) error {
+	fromIdx, toIdx, err := ResolveRange(c, r, policy)
+	if err != nil {
+		return err
+	}
+
+	var (
+		before        = c.EventStream[:fromIdx]
+		base          = c.EventStream[fromIdx].Time
+		header        = c.Header
+		width, height = sizeAt(c.Header, before)
+		events        []*Event
+	)
+
+	if restore != nil && fromIdx > 0 {
+		events, err = restore(c.Header, before)
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore the state at %v", base)
+		}
+	}
+
+	for _, ev := range c.EventStream[fromIdx : toIdx+1] {
+		ev.Time -= base
+		events = append(events, ev)
+	}
+
+	header.Width, header.Height = width, height
+	if header.Timestamp != 0 {
+		header.Timestamp += uint(base / Second)
+	}
+
+	c.Header = header
+	c.EventStream = events
+
+	return nil
+}
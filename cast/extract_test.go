@@ -0,0 +1,74 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestExtract(t *testing.T) {
+	newCast := func() *cast.Cast {
+		return &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 80, Height: 24, Timestamp: 1000},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "a"},
+				{Time: cast.Seconds(2), Type: "r", Data: "100x30"},
+				{Time: cast.Seconds(3.5), Type: "o", Data: "b"},
+				{Time: cast.Seconds(4), Type: "m", Data: "c"},
+				{Time: cast.Seconds(5), Type: "o", Data: "d"},
+			},
+		}
+	}
+
+	t.Run("With nil cast", func(t *testing.T) {
+		assert.Error(t, cast.Extract(nil, cast.Seconds(1), cast.Seconds(2), nil))
+	})
+
+	t.Run("With from after to", func(t *testing.T) {
+		assert.Error(t, cast.Extract(newCast(), cast.Seconds(3), cast.Seconds(2), nil))
+	})
+
+	t.Run("Keeps a range and rebases it", func(t *testing.T) {
+		c := newCast()
+
+		err := cast.Extract(c, cast.Seconds(3.5), cast.Seconds(4), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, cast.Header{Version: 2, Width: 100, Height: 30, Timestamp: 1003}, c.Header)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0), Type: "o", Data: "b"},
+			{Time: cast.Seconds(0.5), Type: "m", Data: "c"},
+		}, c.EventStream)
+	})
+
+	t.Run("Restores the state before the range", func(t *testing.T) {
+		var (
+			c      = newCast()
+			header cast.Header
+			before []*cast.Event
+		)
+
+		err := cast.ExtractRange(c, cast.Range{From: cast.MarkerAt("c"), To: cast.EventAt(-1)}, cast.SnapExact,
+			func(h cast.Header, events []*cast.Event) ([]*cast.Event, error) {
+				header, before = h, events
+				return []*cast.Event{{Time: cast.Seconds(0), Type: "o", Data: "ab"}}, nil
+			})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(80), header.Width)
+		assert.Len(t, before, 3)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0), Type: "o", Data: "ab"},
+			{Time: cast.Seconds(0), Type: "m", Data: "c"},
+			{Time: cast.Seconds(1), Type: "o", Data: "d"},
+		}, c.EventStream)
+	})
+
+	t.Run("With failing restorer", func(t *testing.T) {
+		err := cast.Extract(newCast(), cast.Seconds(2), cast.Seconds(4), func(cast.Header, []*cast.Event) ([]*cast.Event, error) {
+			return nil, errors.New("boom")
+		})
+		assert.Error(t, err)
+	})
+}
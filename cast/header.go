@@ -0,0 +1,248 @@
+package cast
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// headerFields has the same fields as Header but none of its methods,
+// allowing the modeled fields to be (un)marshaled with the default
+// behavior.
+type headerFields Header
+
+// headerKeys are the header keys modeled by Header.
+var headerKeys = jsonKeys(headerFields{})
+
+// UnmarshalJSON decodes an encoded v2 header, keeping any keys that
+// aren't modeled in `Extra`.
+//
+// Fields that aren't present in `data` keep their current values.
+func (header *Header) UnmarshalJSON(data []byte) error {
+	fields := headerFields(*header)
+
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	fields.Extra, err = extraFields(data, headerKeys)
+	if err != nil {
+		return err
+	}
+
+	*header = Header(fields)
+	header.restoreV3Fields()
+
+	return nil
+}
+
+// MarshalJSON encodes the header using the format of its version (see
+// `marshal`).
+func (header *Header) MarshalJSON() ([]byte, error) {
+	return header.marshal()
+}
+
+// marshal encodes the header using the format of its version, appending
+// the keys in `Extra` with their values untouched.
+func (header *Header) marshal() ([]byte, error) {
+	if header.Version == 3 {
+		return marshalWithExtra(header.toV3(), header.Extra)
+	}
+
+	extra, err := header.v2Extra()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalWithExtra((*headerFields)(header), extra)
+}
+
+// v2Term holds what a v2 header keeps of the v3 `term` object: the
+// rest of it is modeled by v2 keys (`width`, `height`, `env.TERM` and
+// `theme`).
+type v2Term struct {
+	Version string `json:"version,omitempty"`
+}
+
+// v2Extra returns the unknown keys of a v2 header along with the
+// fields that v2 doesn't model (tags and what's left of the `term`
+// object), kept under the keys that v3 uses so that converting a cast
+// to v2 and back loses nothing (see `restoreV3Fields`).
+func (header *Header) v2Extra() (map[string]json.RawMessage, error) {
+	if len(header.Tags) == 0 && header.TermVersion == "" && len(header.TermExtra) == 0 {
+		return header.Extra, nil
+	}
+
+	extra := make(map[string]json.RawMessage, len(header.Extra)+2)
+	for key, value := range header.Extra {
+		extra[key] = value
+	}
+
+	if len(header.Tags) > 0 {
+		data, err := json.Marshal(header.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		extra["tags"] = data
+	}
+
+	if header.TermVersion != "" || len(header.TermExtra) > 0 {
+		data, err := marshalWithExtra(&v2Term{Version: header.TermVersion}, header.TermExtra)
+		if err != nil {
+			return nil, err
+		}
+
+		extra["term"] = data
+	}
+
+	return extra, nil
+}
+
+// restoreV3Fields moves the `tags` and `term` keys that `v2Extra` kept
+// in a v2 header back into their fields. Keys that don't look like
+// they were written by `v2Extra` stay in `Extra`.
+func (header *Header) restoreV3Fields() {
+	if raw, ok := header.Extra["tags"]; ok {
+		var tags []string
+		if json.Unmarshal(raw, &tags) == nil && tags != nil {
+			header.Tags = tags
+			delete(header.Extra, "tags")
+		}
+	}
+
+	if raw, ok := header.Extra["term"]; ok {
+		var term v3Term
+		if json.Unmarshal(raw, &term) == nil && term.Cols == 0 && term.Rows == 0 &&
+			term.Type == "" && term.Theme == nil && (term.Version != "" || term.Extra != nil) {
+			header.TermVersion = term.Version
+			header.TermExtra = term.Extra
+			delete(header.Extra, "term")
+		}
+	}
+
+	if len(header.Extra) == 0 {
+		header.Extra = nil
+	}
+}
+
+// themeFields has the same fields as Theme but none of its methods.
+type themeFields Theme
+
+// themeKeys are the theme keys modeled by Theme.
+var themeKeys = jsonKeys(themeFields{})
+
+// UnmarshalJSON decodes an encoded theme, keeping any keys that aren't
+// modeled in `Extra`.
+func (theme *Theme) UnmarshalJSON(data []byte) error {
+	fields := themeFields(*theme)
+
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	fields.Extra, err = extraFields(data, themeKeys)
+	if err != nil {
+		return err
+	}
+
+	*theme = Theme(fields)
+	return nil
+}
+
+// MarshalJSON encodes the theme, appending the keys in `Extra` with
+// their values untouched.
+func (theme *Theme) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra((*themeFields)(theme), theme.Extra)
+}
+
+// jsonKeys lists the JSON keys of the fields of the struct `v`.
+func jsonKeys(v interface{}) map[string]bool {
+	var (
+		typ  = reflect.TypeOf(v)
+		keys = make(map[string]bool, typ.NumField())
+	)
+
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+
+	return keys
+}
+
+// extraFields collects the keys of the JSON object `data` that aren't
+// part of `known`, returning nil if there are none.
+func extraFields(data []byte, known map[string]bool) (map[string]json.RawMessage, error) {
+	var all map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &all)
+	if err != nil {
+		return nil, err
+	}
+
+	var extra map[string]json.RawMessage
+
+	for key, value := range all {
+		if known[key] {
+			continue
+		}
+
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+
+		extra[key] = value
+	}
+
+	return extra, nil
+}
+
+// marshalWithExtra encodes the struct `v` as a JSON object and then
+// appends the keys in `extra` (sorted), copying their values verbatim.
+func marshalWithExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	data := bytes.TrimSpace(buf.Bytes())
+	if len(extra) == 0 {
+		return data, nil
+	}
+
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	data = data[:len(data)-1]
+	for _, key := range keys {
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) > 1 {
+			data = append(data, ',')
+		}
+
+		data = append(data, encodedKey...)
+		data = append(data, ':')
+		data = append(data, extra[key]...)
+	}
+
+	return append(data, '}'), nil
+}
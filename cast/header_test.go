@@ -0,0 +1,100 @@
+package cast_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+const richHeader = `{"version": 2, "width": 80, "height": 24, ` +
+	`"theme": {"fg": "#d0d0d0", "bg": "#212121", "palette": "#151515:#ac4142:#7e8e50:#e5b567:#6c99bb:#9f4e85:#7dd6cf:#d0d0d0"}, ` +
+	`"env": {"SHELL": "/bin/zsh", "TERM": "xterm-256color", "LANG": "en_US.UTF-8"}, ` +
+	`"x_recorder": {"name": "custom",  "flags": [1, 2.50, "<a&b>"]}, "author": "me"}`
+
+func TestHeader_Theme(t *testing.T) {
+	c, err := cast.Decode(strings.NewReader(richHeader))
+	assert.NoError(t, err)
+
+	assert.Equal(t, &cast.Theme{
+		Fg:      "#d0d0d0",
+		Bg:      "#212121",
+		Palette: "#151515:#ac4142:#7e8e50:#e5b567:#6c99bb:#9f4e85:#7dd6cf:#d0d0d0",
+	}, c.Header.Theme)
+
+	assert.Equal(t, map[string]string{
+		"SHELL": "/bin/zsh",
+		"TERM":  "xterm-256color",
+		"LANG":  "en_US.UTF-8",
+	}, c.Header.Env)
+}
+
+func TestHeader_UnknownKeys(t *testing.T) {
+	t.Run("Are kept verbatim through a round trip", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(richHeader + "\n[1, \"o\", \"a\"]\n"))
+		assert.NoError(t, err)
+
+		assert.Len(t, c.Header.Extra, 2)
+		assert.Equal(t, `"me"`, string(c.Header.Extra["author"]))
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Encode(buf))
+
+		header := strings.SplitN(buf.String(), "\n", 2)[0]
+		assert.Contains(t, header, `"author":"me"`)
+		assert.Contains(t, header, `"x_recorder":{"name": "custom",  "flags": [1, 2.50, "<a&b>"]}`)
+		assert.Contains(t, header, `"theme":{"fg":"#d0d0d0"`)
+
+		decoded, err := cast.Decode(strings.NewReader(buf.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, c, decoded)
+	})
+
+	t.Run("Are carried over to v3", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(richHeader))
+		assert.NoError(t, err)
+
+		c.Header.Version = 3
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Encode(buf))
+
+		assert.Contains(t, buf.String(), `"term":{"cols":80,"rows":24,"type":"xterm-256color","theme":{"fg":"#d0d0d0"`)
+		assert.Contains(t, buf.String(), `"author":"me"`)
+
+		decoded, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Header, decoded.Header)
+	})
+
+	t.Run("Are kept inside the theme", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(`{"version": 2, "width": 1, "height": 1, "theme": {"fg": "#fff", "extra": 1}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `1`, string(c.Header.Theme.Extra["extra"]))
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Encode(buf))
+		assert.Equal(t, "{\"version\":2,\"width\":1,\"height\":1,\"theme\":{\"fg\":\"#fff\",\"extra\":1}}\n", buf.String())
+
+		c.Header.Version = 3
+		buf.Reset()
+		assert.NoError(t, c.Encode(buf))
+
+		decoded, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Header.Theme, decoded.Header.Theme)
+	})
+
+	t.Run("Are encoded by Header.Encode", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(`{"version": 2, "width": 1, "height": 1, "a": true}`))
+		assert.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Header.Encode(json.NewEncoder(buf)))
+		assert.Equal(t, "{\"version\":2,\"width\":1,\"height\":1,\"a\":true}\n", buf.String())
+	})
+}
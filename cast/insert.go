@@ -0,0 +1,102 @@
+package cast
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Insert splices the events of `other` into the cast at the time `at`
+// refers to (see `ResolveTime`), right after the events happening at
+// that time. The events that come later are delayed by the duration of
+// `other`, so that their markers still point at the same content.
+//
+// When `other` was recorded with another terminal size, resize (`r`)
+// events are added around its events so that it renders at its own
+// size and the cast gets its size back afterwards. Exit status (`x`)
+// events of `other` are dropped.
+func Insert(c *Cast, other *Cast, at Position) error {
+	if c == nil || other == nil {
+		return errors.Errorf("cast must not be nil")
+	}
+
+	t, err := ResolveTime(c, at)
+	if err != nil {
+		return err
+	}
+
+	if t < 0 {
+		return errors.Errorf("insertion time must not be negative")
+	}
+
+	var (
+		split = sort.Search(len(c.EventStream), func(i int) bool {
+			return c.EventStream[i].Time > t
+		})
+		width, height = sizeAt(c.Header, c.EventStream[:split])
+		events        = make([]*Event, 0, len(c.EventStream)+len(other.EventStream)+2)
+		duration      Time
+	)
+
+	if n := len(other.EventStream); n > 0 {
+		duration = other.EventStream[n-1].Time
+	}
+
+	events = append(events, c.EventStream[:split]...)
+
+	resize := func(at Time, fromW, fromH, toW, toH uint) {
+		if fromW != toW || fromH != toH {
+			events = append(events, &Event{
+				Time: at,
+				Type: "r",
+				Data: fmt.Sprintf("%dx%d", toW, toH),
+			})
+		}
+	}
+
+	resize(t, width, height, other.Header.Width, other.Header.Height)
+
+	for _, ev := range other.EventStream {
+		if ev.Type == "x" {
+			continue
+		}
+
+		events = append(events, &Event{
+			Time: t + ev.Time,
+			Type: ev.Type,
+			Data: ev.Data,
+		})
+	}
+
+	otherWidth, otherHeight := sizeAt(other.Header, other.EventStream)
+	resize(t+duration, otherWidth, otherHeight, width, height)
+
+	for _, ev := range c.EventStream[split:] {
+		ev.Time += duration
+		events = append(events, ev)
+	}
+
+	c.EventStream = events
+
+	return nil
+}
+
+// sizeAt returns the terminal size after `events`, starting from the
+// size in `header`.
+func sizeAt(header Header, events []*Event) (width, height uint) {
+	width, height = header.Width, header.Height
+
+	for _, ev := range events {
+		if ev.Type != "r" {
+			continue
+		}
+
+		w, h, err := ParseSize(ev.Data)
+		if err == nil {
+			width, height = uint(w), uint(h)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,91 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestInsert(t *testing.T) {
+	var (
+		newCast = func() *cast.Cast {
+			return &cast.Cast{
+				Header: cast.Header{Version: 2, Width: 80, Height: 24},
+				EventStream: []*cast.Event{
+					{Time: cast.Seconds(1), Type: "o", Data: "a"},
+					{Time: cast.Seconds(2), Type: "m", Data: "step"},
+					{Time: cast.Seconds(3), Type: "o", Data: "b"},
+				},
+			}
+		}
+		other = &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 80, Height: 24},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(0.5), Type: "o", Data: "x"},
+				{Time: cast.Seconds(1.5), Type: "x", Data: "0"},
+			},
+		}
+	)
+
+	t.Run("With nil casts", func(t *testing.T) {
+		assert.Error(t, cast.Insert(nil, other, cast.TimeAt(cast.Seconds(1))))
+		assert.Error(t, cast.Insert(newCast(), nil, cast.TimeAt(cast.Seconds(1))))
+	})
+
+	t.Run("With unknown marker", func(t *testing.T) {
+		assert.Error(t, cast.Insert(newCast(), other, cast.MarkerAt("nope")))
+	})
+
+	t.Run("Inserts a cast and delays what follows", func(t *testing.T) {
+		c := newCast()
+
+		err := cast.Insert(c, other, cast.TimeAt(cast.Seconds(1.5)))
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(2), Type: "o", Data: "x"},
+			{Time: cast.Seconds(3.5), Type: "m", Data: "step"},
+			{Time: cast.Seconds(4.5), Type: "o", Data: "b"},
+		}, c.EventStream)
+	})
+
+	t.Run("Inserts after the events at a marker", func(t *testing.T) {
+		c := newCast()
+
+		err := cast.Insert(c, other, cast.MarkerAt("step"))
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(2), Type: "m", Data: "step"},
+			{Time: cast.Seconds(2.5), Type: "o", Data: "x"},
+			{Time: cast.Seconds(4.5), Type: "o", Data: "b"},
+		}, c.EventStream)
+	})
+
+	t.Run("Handles size differences", func(t *testing.T) {
+		c := newCast()
+		c.EventStream[1] = &cast.Event{Time: cast.Seconds(2), Type: "r", Data: "100x30"}
+
+		wide := &cast.Cast{
+			Header: cast.Header{Version: 2, Width: 120, Height: 40},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(0.5), Type: "o", Data: "x"},
+				{Time: cast.Seconds(1), Type: "r", Data: "90x20"},
+			},
+		}
+
+		err := cast.Insert(c, wide, cast.TimeAt(cast.Seconds(2)))
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(2), Type: "r", Data: "100x30"},
+			{Time: cast.Seconds(2), Type: "r", Data: "120x40"},
+			{Time: cast.Seconds(2.5), Type: "o", Data: "x"},
+			{Time: cast.Seconds(3), Type: "r", Data: "90x20"},
+			{Time: cast.Seconds(3), Type: "r", Data: "100x30"},
+			{Time: cast.Seconds(4), Type: "o", Data: "b"},
+		}, c.EventStream)
+	})
+}
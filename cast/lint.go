@@ -0,0 +1,353 @@
+package cast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Severity tells how serious a problem found by `Lint` is.
+type Severity uint8
+
+const (
+	// SeverityWarning marks problems that don't keep the cast from
+	// being decoded but that are likely to be mistakes.
+	SeverityWarning Severity = iota
+
+	// SeverityError marks problems that make the cast invalid.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+
+	return "warning"
+}
+
+// Problem is an issue found in an encoded cast.
+type Problem struct {
+	// Line is the line of the encoded cast (starting at 1) where the
+	// problem is, or zero if it can't be tied to a line (as in v1
+	// casts, made of a single JSON document).
+	Line int
+
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+	}
+
+	return fmt.Sprintf("%d: %s: %s", p.Line, p.Severity, p.Message)
+}
+
+// Lint reads an encoded cast and reports every problem it finds, in the
+// order of the lines they're at, instead of stopping at the first one
+// like `Decode` and `Cast.Validate` do.
+//
+// Besides what makes a cast invalid (malformed lines, unsupported
+// versions, missing dimensions, unknown event types, exit status events
+// outside of v3, negative or out-of-order times and malformed resize
+// events), it warns about invalid UTF-8, unknown header keys, empty
+// markers and output lines that don't fit the width of the terminal.
+//
+// An error is only returned if the cast couldn't be read.
+func Lint(reader io.Reader) ([]Problem, error) {
+	if reader == nil {
+		return nil, errors.New("a reader must be specified")
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read cast")
+	}
+
+	l := &linter{}
+	l.lint(data)
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		return l.problems[i].Line < l.problems[j].Line
+	})
+
+	return l.problems, nil
+}
+
+// linter holds the state of the checks of a cast.
+type linter struct {
+	problems []Problem
+
+	version  uint8
+	width    int
+	lastTime Time
+
+	// text, column and overflown track the output to find lines that
+	// don't fit the terminal.
+	text      textScanner
+	column    int
+	overflown bool
+}
+
+func (l *linter) report(line int, severity Severity, format string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) lint(data []byte) {
+	lines := bytes.Split(data, []byte("\n"))
+
+	for idx, line := range lines {
+		if !utf8.Valid(line) {
+			l.report(idx+1, SeverityWarning,
+				"invalid UTF-8 (decoded as U+FFFD replacement characters)")
+		}
+	}
+
+	headerIdx := 0
+	for headerIdx < len(lines) && len(bytes.TrimSpace(lines[headerIdx])) == 0 {
+		headerIdx++
+	}
+
+	if headerIdx == len(lines) {
+		l.report(0, SeverityError, "cast is empty")
+		return
+	}
+
+	if !l.lintHeader(headerIdx+1, lines[headerIdx]) {
+		// v1 casts are a single JSON document, which may span lines.
+		rest := bytes.Join(lines[headerIdx:], []byte("\n"))
+		if version, err := probeVersion(rest); err == nil && version == 1 {
+			l.lintV1(rest)
+			return
+		}
+
+		l.report(headerIdx+1, SeverityError, "malformed header: not a JSON object")
+		l.version = 2
+	}
+
+	for idx := headerIdx + 1; idx < len(lines); idx++ {
+		if len(bytes.TrimSpace(lines[idx])) == 0 {
+			continue
+		}
+
+		l.lintEventLine(idx+1, lines[idx])
+	}
+}
+
+// lintHeader checks the header of a v2 or v3 cast, telling whether the
+// line holds a JSON object at all.
+func (l *linter) lintHeader(line int, raw []byte) bool {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil {
+		return false
+	}
+
+	var header Header
+
+	version, err := probeVersion(raw)
+	if err != nil {
+		l.report(line, SeverityError, "malformed version: %v", err)
+		l.version = 2
+		return true
+	}
+
+	l.version = version
+
+	switch version {
+	case 2:
+		err = json.Unmarshal(raw, &header)
+	case 3:
+		header, err = decodeV3Header(raw)
+	case 1:
+		l.report(line, SeverityError, "v1 casts must be a single JSON document")
+		l.version = 2
+		return true
+	default:
+		l.report(line, SeverityError, "unsupported version %d: must be 1, 2 or 3", version)
+		l.version = 2
+		return true
+	}
+
+	if err != nil {
+		l.report(line, SeverityError, "malformed header: %v", errors.Cause(err))
+		return true
+	}
+
+	l.lintHeaderFields(line, header)
+
+	return true
+}
+
+func (l *linter) lintHeaderFields(line int, header Header) {
+	if header.Width == 0 {
+		l.report(line, SeverityError, "a valid width (>0) must be specified")
+	}
+
+	if header.Height == 0 {
+		l.report(line, SeverityError, "a valid height (>0) must be specified")
+	}
+
+	keys := make([]string, 0, len(header.Extra))
+	for key := range header.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		l.report(line, SeverityWarning, "unknown header key %q", key)
+	}
+
+	l.width = int(header.Width)
+}
+
+func (l *linter) lintV1(raw []byte) {
+	header, events, err := decodeV1(raw)
+	if err != nil {
+		l.report(0, SeverityError, "%v", err)
+		return
+	}
+
+	l.version = 1
+	l.lintHeaderFields(0, header)
+
+	for _, ev := range events {
+		l.lintEvent(0, ev)
+	}
+}
+
+// lintEventLine checks a line of the event stream.
+func (l *linter) lintEventLine(line int, raw []byte) {
+	var fields []interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&fields)
+	if err != nil {
+		l.report(line, SeverityError, "malformed event: not a JSON array")
+		return
+	}
+
+	if len(fields) != 3 {
+		l.report(line, SeverityError, "malformed event: must have 3 elements, has %d", len(fields))
+		return
+	}
+
+	number, ok := fields[0].(json.Number)
+	if !ok {
+		l.report(line, SeverityError, "malformed event: time must be a number")
+		return
+	}
+
+	time, err := ParseTime(number.String())
+	if err != nil {
+		l.report(line, SeverityError, "malformed event: %v", err)
+		return
+	}
+
+	evType, ok := fields[1].(string)
+	if !ok {
+		l.report(line, SeverityError, "malformed event: type must be a string")
+		return
+	}
+
+	data, ok := fields[2].(string)
+	if !ok {
+		l.report(line, SeverityError, "malformed event: data must be a string")
+		return
+	}
+
+	if l.version == 3 {
+		if time < 0 {
+			l.report(line, SeverityError, "negative interval %v", time)
+			time = 0
+		}
+
+		time += l.lastTime
+	}
+
+	l.lintEvent(line, &Event{Time: time, Type: evType, Data: data})
+}
+
+// lintEvent checks an event of the event stream.
+func (l *linter) lintEvent(line int, ev *Event) {
+	switch {
+	case ev.Time < 0:
+		l.report(line, SeverityError, "negative time %v", ev.Time)
+	case ev.Time < l.lastTime:
+		l.report(line, SeverityError,
+			"out-of-order time %v: the previous event is at %v", ev.Time, l.lastTime)
+	default:
+		l.lastTime = ev.Time
+	}
+
+	err := ev.ValidateEvent()
+	if err != nil {
+		l.report(line, SeverityError, "invalid event type %q: %v", ev.Type, err)
+		return
+	}
+
+	switch ev.Type {
+	case "x":
+		if l.version != 3 {
+			l.report(line, SeverityError, "exit status ('x') events are only valid in v3 casts")
+		}
+	case "o":
+		l.lintOutput(line, ev.Data)
+	case "r":
+		width, _, err := ParseSize(ev.Data)
+		if err != nil {
+			l.report(line, SeverityError, "malformed resize event: %v (must be COLSxROWS)", err)
+			return
+		}
+
+		l.width = width
+	case "m":
+		if ev.Data == "" {
+			l.report(line, SeverityWarning, "empty marker")
+		}
+	}
+}
+
+// lintOutput follows the column that output gets printed at, warning
+// about lines longer than the width of the terminal. Escape sequences
+// are skipped and characters take as many columns as they're displayed
+// with (two for wide characters, none for combining marks).
+func (l *linter) lintOutput(line int, data string) {
+	for _, r := range data {
+		if !l.text.printable(r) {
+			switch r {
+			case '\r', '\n':
+				l.column = 0
+				l.overflown = false
+			case '\b':
+				if l.column > 0 {
+					l.column--
+				}
+			case '\t':
+				l.column += 8 - l.column%8
+			}
+
+			continue
+		}
+
+		l.column += RuneWidth(r)
+
+		if l.width > 0 && l.column > l.width && !l.overflown {
+			l.report(line, SeverityWarning,
+				"output line doesn't fit the terminal width (%d columns)", l.width)
+			l.overflown = true
+		}
+	}
+}
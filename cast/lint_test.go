@@ -0,0 +1,118 @@
+package cast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestLint(t *testing.T) {
+	lint := func(input string) []string {
+		problems, err := cast.Lint(strings.NewReader(input))
+		assert.NoError(t, err)
+
+		res := make([]string, len(problems))
+		for idx, problem := range problems {
+			res[idx] = problem.String()
+		}
+
+		return res
+	}
+
+	t.Run("With nil reader", func(t *testing.T) {
+		_, err := cast.Lint(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("With an empty cast", func(t *testing.T) {
+		assert.Equal(t, []string{"error: cast is empty"}, lint("\n"))
+	})
+
+	t.Run("With a valid cast", func(t *testing.T) {
+		assert.Empty(t, lint(`{"version": 2, "width": 5, "height": 2}
+[0.5, "o", "\u001b]0;a long title\u0007\u001b[1;31mhello\u001b[0m\r\n"]
+[1, "r", "8x2"]
+[1.5, "o", "é́ world"]
+[2, "m", "end"]
+`))
+	})
+
+	t.Run("Collects every problem", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`1: warning: unknown header key "foo"`,
+			`4: error: out-of-order time 0.500000: the previous event is at 1.000000`,
+			`4: warning: output line doesn't fit the terminal width (5 columns)`,
+			`5: error: malformed resize event: malformed size "80" (must be COLSxROWS)`,
+			`6: warning: empty marker`,
+			`7: error: negative time -1.000000`,
+			`7: error: invalid event type "z": type must either be 'o', 'i', 'r', 'm' or 'x'`,
+			`8: error: malformed event: not a JSON array`,
+			`9: error: malformed event: must have 3 elements, has 2`,
+			`10: warning: invalid UTF-8 (decoded as U+FFFD replacement characters)`,
+		}, lint(`{"version": 2, "width": 5, "height": 2, "foo": 1}
+
+[1, "o", "abc"]
+[0.5, "o", "def\r\nxyz"]
+[2, "r", "80"]
+[3, "m", ""]
+[-1, "z", "x"]
+not json
+[4, "o"]
+[5, "o", "`+"\xff"+`"]`))
+	})
+
+	t.Run("With an invalid header", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`1: error: unsupported version 4: must be 1, 2 or 3`,
+			`2: error: malformed event: not a JSON array`,
+		}, lint("{\"version\": 4}\n[1, \"o\"\n"))
+
+		assert.Equal(t, []string{
+			`1: error: a valid width (>0) must be specified`,
+		}, lint(`{"version": 2, "height": 2}`))
+	})
+
+	t.Run("With a v3 cast", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`3: error: negative interval -1.000000`,
+		}, lint(`{"version": 3, "term": {"cols": 5, "rows": 2}}
+[1, "o", "a"]
+[-1, "o", "b"]
+`))
+	})
+
+	t.Run("With exit status events", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`3: error: exit status ('x') events are only valid in v3 casts`,
+		}, lint(`{"version": 2, "width": 5, "height": 2}
+[1, "o", "a"]
+[2, "x", "0"]
+`))
+
+		assert.Empty(t, lint(`{"version": 3, "term": {"cols": 5, "rows": 2}}
+[1, "o", "a"]
+[1, "x", "0"]
+`))
+	})
+
+	t.Run("With wide characters", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`3: warning: output line doesn't fit the terminal width (5 columns)`,
+		}, lint(`{"version": 2, "width": 5, "height": 2}
+[1, "o", "日本\r\n"]
+[2, "o", "日本語"]
+`))
+	})
+
+	t.Run("With a v1 cast", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`warning: unknown header key "foo"`,
+		}, lint(`{
+  "version": 1, "width": 5, "height": 2, "foo": true,
+  "stdout": [[0.5, "abc"], [1, "de"]]
+}`))
+	})
+}
@@ -0,0 +1,192 @@
+package cast
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultPrompt matches the end of the line that usual shell prompts
+// leave the cursor at (`$`, `#`, `%`, `>` or `❯` followed by a space).
+var DefaultPrompt = regexp.MustCompile(`[$#%>❯] $`)
+
+// PaceOptions tunes how `Pace` gives viewers time to follow a cast.
+type PaceOptions struct {
+	// AfterOutput is the minimum delay between the end of the output
+	// of a command and whatever comes next, so that it can be read.
+	AfterOutput Time
+
+	// BeforeInput is the minimum delay between a prompt being drawn and
+	// whatever comes next (usually the first key typed at it).
+	BeforeInput Time
+
+	// Hold is the minimum time that the final frame stays on screen.
+	Hold Time
+
+	// Prompt matches the end of the line that a prompt leaves the cursor
+	// at, ignoring escape sequences (`DefaultPrompt` if nil).
+	Prompt *regexp.Regexp
+
+	// Idle is the delay after which output ending with a new line is
+	// considered to be the end of the output of a command, even if no
+	// prompt gets drawn right after it.
+	Idle Time
+}
+
+// Pace lengthens the delays of a cast that are too short to follow it,
+// as `Quantize` can only shorten them, returning how many delays got
+// lengthened. The events after a lengthened delay are shifted.
+//
+// The points where a command finishes are found by following the output
+// (`o`) events: an event that leaves a prompt at the end of the line is
+// a prompt redraw, which comes right after the output of a command, and
+// output ending with a new line followed by at least `Idle` of
+// inactivity ends the output of a command as well.
+//
+// Unless the cast already lasts long enough after its last output (or
+// resize) event, an empty output event is added (and the exit status
+// (`x`) event moved after it) so that the final frame is held for `Hold`.
+func Pace(c *Cast, opts PaceOptions) (count int, err error) {
+	if c == nil {
+		err = errors.Errorf("cast must not be nil")
+		return
+	}
+
+	if opts.AfterOutput < 0 || opts.BeforeInput < 0 || opts.Hold < 0 || opts.Idle < 0 {
+		err = errors.Errorf("delays must not be negative")
+		return
+	}
+
+	if len(c.EventStream) == 0 {
+		return
+	}
+
+	if opts.Prompt == nil {
+		opts.Prompt = DefaultPrompt
+	}
+
+	var (
+		events   = c.EventStream
+		minimums = paceMinimums(events, opts)
+		prevTime = events[0].Time
+		newTime  = events[0].Time
+	)
+
+	for idx, ev := range events[1:] {
+		delta := ev.Time - prevTime
+		if delta < minimums[idx] {
+			delta = minimums[idx]
+			count++
+		}
+
+		prevTime = ev.Time
+		newTime += delta
+		ev.Time = newTime
+	}
+
+	if paceHold(c, opts.Hold) {
+		count++
+	}
+
+	return
+}
+
+// paceMinimums returns the minimum delay between each event and the
+// next one.
+func paceMinimums(events []*Event, opts PaceOptions) []Time {
+	var (
+		scanner  textScanner
+		line     strings.Builder
+		prompts  = make([]bool, len(events))
+		finished = make([]bool, len(events))
+		minimums = make([]Time, len(events))
+	)
+
+	for idx, ev := range events {
+		if ev.Type != "o" {
+			continue
+		}
+
+		drawn := false
+
+		for _, r := range ev.Data {
+			ground := scanner.state == textGround
+
+			switch {
+			case scanner.printable(r):
+				line.WriteRune(r)
+				drawn = true
+			case ground && (r == '\n' || r == '\r'):
+				line.Reset()
+			}
+		}
+
+		prompts[idx] = drawn && opts.Prompt.MatchString(line.String())
+		finished[idx] = drawn && line.Len() == 0 && strings.HasSuffix(ev.Data, "\n")
+	}
+
+	for idx := range events[:len(events)-1] {
+		var (
+			ev   = events[idx]
+			next = events[idx+1]
+		)
+
+		if ev.Type != "o" {
+			continue
+		}
+
+		switch {
+		case prompts[idx]:
+			minimums[idx] = opts.BeforeInput
+
+			// the output of the command came along with the prompt.
+			if strings.Contains(ev.Data, "\n") && opts.AfterOutput > minimums[idx] {
+				minimums[idx] = opts.AfterOutput
+			}
+		case prompts[idx+1]:
+			minimums[idx] = opts.AfterOutput
+		case finished[idx] && next.Time-ev.Time >= opts.Idle:
+			minimums[idx] = opts.AfterOutput
+		}
+	}
+
+	return minimums
+}
+
+// paceHold makes the final frame of a cast last at least `hold`,
+// telling whether the cast had to be made longer.
+func paceHold(c *Cast, hold Time) bool {
+	var (
+		events = c.EventStream
+		last   = events[len(events)-1]
+		drawn  = -1
+	)
+
+	for idx := len(events) - 1; idx >= 0; idx-- {
+		if events[idx].Type == "o" || events[idx].Type == "r" {
+			drawn = idx
+			break
+		}
+	}
+
+	if drawn == -1 || last.Time-events[drawn].Time >= hold {
+		return false
+	}
+
+	var (
+		end  = events[drawn].Time + hold
+		held = &Event{Time: end, Type: "o", Data: ""}
+	)
+
+	// the exit status isn't enough on its own, as v2 casts leave it out.
+	if last.Type == "x" {
+		last.Time = end
+		c.EventStream = append(events[:len(events)-1], held, last)
+		return true
+	}
+
+	c.EventStream = append(c.EventStream, held)
+
+	return true
+}
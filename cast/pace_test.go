@@ -0,0 +1,173 @@
+package cast_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestPace(t *testing.T) {
+	opts := cast.PaceOptions{
+		AfterOutput: cast.Seconds(1),
+		BeforeInput: cast.Seconds(0.5),
+		Idle:        cast.Seconds(0.5),
+	}
+
+	times := func(c *cast.Cast) (res []cast.Time) {
+		for _, ev := range c.EventStream {
+			res = append(res, ev.Time)
+		}
+		return
+	}
+
+	t.Run("Parameter validation", func(t *testing.T) {
+		t.Run("With nil cast", func(t *testing.T) {
+			_, err := cast.Pace(nil, opts)
+			assert.Error(t, err)
+		})
+
+		t.Run("With a negative delay", func(t *testing.T) {
+			_, err := cast.Pace(&cast.Cast{}, cast.PaceOptions{Hold: cast.Seconds(-1)})
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("Waits before the input at a prompt", func(t *testing.T) {
+		data := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "\x1b[32mme\x1b[0m:~$ "},
+				{Time: cast.Seconds(1.1), Type: "o", Data: "l"},
+				{Time: cast.Seconds(1.2), Type: "o", Data: "s"},
+			},
+		}
+
+		count, err := cast.Pace(data, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(1.5), cast.Seconds(1.6),
+		}, times(data))
+	})
+
+	t.Run("Waits after the output before a prompt", func(t *testing.T) {
+		data := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "a.txt"},
+				{Time: cast.Seconds(1.1), Type: "o", Data: "\r\n$ "},
+				{Time: cast.Seconds(2), Type: "o", Data: "l"},
+			},
+		}
+
+		count, err := cast.Pace(data, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(2), cast.Seconds(3),
+		}, times(data))
+	})
+
+	t.Run("Waits after output followed by inactivity", func(t *testing.T) {
+		data := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "building\r\n"},
+				{Time: cast.Seconds(1.6), Type: "o", Data: "done\r\n"},
+				{Time: cast.Seconds(1.7), Type: "o", Data: "more\r\n"},
+			},
+		}
+
+		count, err := cast.Pace(data, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(2), cast.Seconds(2.1),
+		}, times(data))
+	})
+
+	t.Run("Doesn't take input echoes for prompts", func(t *testing.T) {
+		data := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "l"},
+				{Time: cast.Seconds(1.1), Type: "o", Data: "s"},
+				{Time: cast.Seconds(1.2), Type: "i", Data: "$ "},
+			},
+		}
+
+		count, err := cast.Pace(data, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(1.1), cast.Seconds(1.2),
+		}, times(data))
+	})
+
+	t.Run("Uses a custom prompt", func(t *testing.T) {
+		data := &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "λ "},
+				{Time: cast.Seconds(1.1), Type: "o", Data: "l"},
+			},
+		}
+
+		custom := opts
+		custom.Prompt = regexp.MustCompile(`λ $`)
+
+		count, err := cast.Pace(data, custom)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, cast.Seconds(1.5), data.EventStream[1].Time)
+	})
+
+	t.Run("Holds the final frame", func(t *testing.T) {
+		hold := opts
+		hold.Hold = cast.Seconds(2)
+
+		t.Run("Moving the exit status", func(t *testing.T) {
+			data := &cast.Cast{
+				EventStream: []*cast.Event{
+					{Time: cast.Seconds(1), Type: "o", Data: "a"},
+					{Time: cast.Seconds(1.5), Type: "x", Data: "0"},
+				},
+			}
+
+			count, err := cast.Pace(data, hold)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Equal(t, []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "a"},
+				{Time: cast.Seconds(3), Type: "o"},
+				{Time: cast.Seconds(3), Type: "x", Data: "0"},
+			}, data.EventStream)
+		})
+
+		t.Run("Adding an empty event", func(t *testing.T) {
+			data := &cast.Cast{
+				EventStream: []*cast.Event{
+					{Time: cast.Seconds(1), Type: "o", Data: "a"},
+				},
+			}
+
+			count, err := cast.Pace(data, hold)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Len(t, data.EventStream, 2)
+			assert.Equal(t, &cast.Event{Time: cast.Seconds(3), Type: "o"}, data.EventStream[1])
+		})
+
+		t.Run("Unless it's already long enough", func(t *testing.T) {
+			data := &cast.Cast{
+				EventStream: []*cast.Event{
+					{Time: cast.Seconds(1), Type: "o", Data: "a"},
+					{Time: cast.Seconds(4), Type: "x", Data: "0"},
+				},
+			}
+
+			count, err := cast.Pace(data, hold)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, count)
+			assert.Equal(t, cast.Seconds(4), data.EventStream[1].Time)
+		})
+	})
+}
@@ -0,0 +1,578 @@
+package cast
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PositionKind indicates how a `Position` refers to the event stream.
+type PositionKind uint8
+
+const (
+	// PositionTime refers to a timestamp of the recording.
+	PositionTime PositionKind = iota
+
+	// PositionIndex refers to an event by its index in the event stream.
+	PositionIndex
+
+	// PositionMarker refers to the first marker (`m`) event with a given
+	// label.
+	PositionMarker
+)
+
+// Position identifies a point of the event stream, either by a timestamp,
+// by the index of an event or by the label of a marker.
+type Position struct {
+	// Kind tells which of the fields below is meaningful.
+	Kind PositionKind
+
+	// Time is the time elapsed since the beginning of the recording.
+	Time Time
+
+	// Index is the zero-based index of an event in the event stream.
+	//
+	// Negative values count from the end of the stream (`-1` being the
+	// last event).
+	Index int
+
+	// Label is the label of the marker event that the position refers to.
+	Label string
+}
+
+// TimeAt creates a position that refers to a timestamp.
+func TimeAt(t Time) Position {
+	return Position{Kind: PositionTime, Time: t}
+}
+
+// EventAt creates a position that refers to an event index.
+func EventAt(idx int) Position {
+	return Position{Kind: PositionIndex, Index: idx}
+}
+
+// MarkerAt creates a position that refers to the marker labeled `label`.
+func MarkerAt(label string) Position {
+	return Position{Kind: PositionMarker, Label: label}
+}
+
+// String returns the position in a format that `ParsePosition` accepts.
+func (p Position) String() string {
+	switch p.Kind {
+	case PositionIndex:
+		return "#" + strconv.Itoa(p.Index)
+	case PositionMarker:
+		return "@" + p.Label
+	default:
+		return p.Time.String()
+	}
+}
+
+// ParsePosition parses a textual reference to a point of the event stream.
+//
+// The following forms are accepted:
+//
+//   - `12.5`: seconds since the beginning of the recording;
+//   - `1:23.456` or `1:02:03`: `[hh:]mm:ss[.fff]`;
+//   - `1m23s`, `1h2m`, `500ms`: a Go duration; and
+//   - `#12` or `#-1`: an event index (negative indexes count from the end); and
+//   - `@label`: the first marker event labeled `label`.
+func ParsePosition(input string) (res Position, err error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		err = errors.Errorf("position must not be empty")
+		return
+	}
+
+	if strings.HasPrefix(input, "@") {
+		if len(input) == 1 {
+			err = errors.Errorf("marker label must not be empty")
+			return
+		}
+
+		res = MarkerAt(input[1:])
+		return
+	}
+
+	if strings.HasPrefix(input, "#") {
+		var idx int
+
+		idx, err = strconv.Atoi(input[1:])
+		if err != nil {
+			err = errors.Errorf("malformed event index '%s'", input)
+			return
+		}
+
+		res = EventAt(idx)
+		return
+	}
+
+	var t Time
+
+	switch {
+	case strings.Contains(input, ":"):
+		t, err = parseClock(input)
+	case strings.IndexFunc(input, isUnitLetter) != -1:
+		var d time.Duration
+
+		d, err = time.ParseDuration(input)
+		t = Time(d.Round(time.Microsecond) / time.Microsecond)
+	default:
+		t, err = ParseTime(input)
+	}
+
+	if err != nil {
+		err = errors.Errorf("malformed time '%s'", input)
+		return
+	}
+
+	if t < 0 {
+		err = errors.Errorf("time must be a non-negative number '%s'", input)
+		return
+	}
+
+	res = TimeAt(t)
+	return
+}
+
+func isUnitLetter(r rune) bool {
+	return r == 'h' || r == 'm' || r == 's' || r == 'u' || r == 'µ' || r == 'n'
+}
+
+// parseClock parses `[hh:]mm:ss[.fff]`.
+func parseClock(input string) (t Time, err error) {
+	cols := strings.Split(input, ":")
+	if len(cols) > 3 {
+		err = errors.Errorf("too many fields")
+		return
+	}
+
+	for idx, col := range cols {
+		var value Time
+
+		last := idx == len(cols)-1
+		if last {
+			value, err = ParseTime(col)
+		} else {
+			var n int
+
+			n, err = strconv.Atoi(col)
+			value = Time(n) * Second
+		}
+
+		if err != nil {
+			return
+		}
+
+		if value < 0 || (idx > 0 && value >= Minute) {
+			err = errors.Errorf("field out of range")
+			return
+		}
+
+		t = t*60 + value
+	}
+
+	return
+}
+
+// SnapPolicy determines how a time that doesn't exactly match an event
+// gets snapped to one.
+type SnapPolicy uint8
+
+const (
+	// SnapExact requires the time to exactly match an event timestamp.
+	SnapExact SnapPolicy = iota
+
+	// SnapNearest picks the event closest to the time (the earliest one
+	// in case of a tie).
+	SnapNearest
+
+	// SnapEnclose widens a range so that it encloses the times: `from`
+	// snaps to the last event at or before it and `to` to the first
+	// event at or after it.
+	SnapEnclose
+
+	// SnapWithin narrows a range so that it lies within the times: `from`
+	// snaps to the first event at or after it and `to` to the last
+	// event at or before it.
+	SnapWithin
+)
+
+var snapPolicyNames = []string{
+	SnapExact:   "exact",
+	SnapNearest: "nearest",
+	SnapEnclose: "enclose",
+	SnapWithin:  "within",
+}
+
+// String returns the name of the policy as accepted by `ParseSnapPolicy`.
+func (p SnapPolicy) String() string {
+	if int(p) < len(snapPolicyNames) {
+		return snapPolicyNames[p]
+	}
+
+	return "unknown"
+}
+
+// ParseSnapPolicy converts the name of a policy (`exact`, `nearest`,
+// `enclose` or `within`) into a SnapPolicy.
+func ParseSnapPolicy(input string) (SnapPolicy, error) {
+	for policy, name := range snapPolicyNames {
+		if name == input {
+			return SnapPolicy(policy), nil
+		}
+	}
+
+	return SnapExact, errors.Errorf(
+		"unknown snap policy '%s': must be one of %s",
+		input, strings.Join(snapPolicyNames, ", "))
+}
+
+// Range delimits a piece of the event stream (both ends included).
+type Range struct {
+	// From indicates the start of the range.
+	From Position
+
+	// To indicates the end of the range.
+	To Position
+}
+
+// Validate verifies that the range isn't trivially reversed.
+func (r Range) Validate() error {
+	if r.From.Kind != r.To.Kind {
+		return nil
+	}
+
+	switch r.From.Kind {
+	case PositionTime:
+		if r.From.Time > r.To.Time {
+			return errors.Errorf("`from` cant be bigger than `to`")
+		}
+	case PositionIndex:
+		if r.From.Index >= 0 && r.To.Index >= 0 && r.From.Index > r.To.Index {
+			return errors.Errorf("`from` cant be bigger than `to`")
+		}
+	}
+
+	return nil
+}
+
+// String returns the range as `from,to`.
+func (r Range) String() string {
+	return r.From.String() + "," + r.To.String()
+}
+
+// ParseRange parses a range written as `from,to`, each end being a
+// position (see `ParsePosition`).
+func ParseRange(input string) (r Range, err error) {
+	parts := strings.Split(input, ",")
+	if len(parts) != 2 {
+		err = errors.Errorf("range '%s' must be written as 'from,to'", input)
+		return
+	}
+
+	r.From, err = ParsePosition(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return
+	}
+
+	r.To, err = ParsePosition(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return
+	}
+
+	err = r.Validate()
+	return
+}
+
+// resolvedRange is a range along with the indexes of the events it
+// refers to.
+type resolvedRange struct {
+	Range
+
+	// idx is the position of the range in the list it came from.
+	idx int
+
+	fromIdx, toIdx int
+}
+
+// resolveRanges resolves several ranges against the same event stream
+// (see `ResolveRange`), making sure that they don't overlap; ranges
+// sharing an event at their boundaries only overlap if `shared` is
+// false.
+//
+// The resolved ranges are sorted from the last one to the first one, so
+// that transforming the events of a range never shifts the events that
+// the next ones refer to.
+func resolveRanges(c *Cast, ranges []Range, policy SnapPolicy, shared bool) ([]resolvedRange, error) {
+	if len(ranges) == 0 {
+		return nil, errors.Errorf("at least one range must be specified")
+	}
+
+	resolved := make([]resolvedRange, len(ranges))
+
+	for idx, r := range ranges {
+		fromIdx, toIdx, err := ResolveRange(c, r, policy)
+		if err != nil {
+			if len(ranges) > 1 {
+				err = errors.Wrapf(err, "range %s", r)
+			}
+
+			return nil, err
+		}
+
+		resolved[idx] = resolvedRange{Range: r, idx: idx, fromIdx: fromIdx, toIdx: toIdx}
+	}
+
+	sort.SliceStable(resolved, func(i, j int) bool {
+		return resolved[i].fromIdx > resolved[j].fromIdx
+	})
+
+	for i := 1; i < len(resolved); i++ {
+		var (
+			earlier = resolved[i]
+			later   = resolved[i-1]
+		)
+
+		if later.fromIdx < earlier.toIdx || (later.fromIdx == earlier.toIdx && !shared) {
+			first, second := earlier, later
+			if first.idx > second.idx {
+				first, second = second, first
+			}
+
+			return nil, errors.Errorf("ranges %s and %s overlap", first.Range, second.Range)
+		}
+	}
+
+	return resolved, nil
+}
+
+// eventCursor describes an event along with its surroundings so that
+// positions can be matched against it without looking at the whole
+// stream.
+//
+// Events sharing the same timestamp form a group.
+type eventCursor struct {
+	idx   int
+	event *Event
+
+	// firstOfGroup and lastOfGroup tell whether the event is the first
+	// (last) one having its timestamp.
+	firstOfGroup bool
+	lastOfGroup  bool
+
+	// prevTime is the timestamp of the group right before the event's.
+	prevTime Time
+	hasPrev  bool
+
+	// nextTime is the timestamp of the group right after the event's.
+	nextTime Time
+	hasNext  bool
+
+	// remaining is the number of events that come after this one, or
+	// `-1` if that's not known yet.
+	remaining int
+}
+
+// forEachCursor calls `fn` with a cursor for every event of the stream,
+// stopping as soon as `fn` returns false.
+func forEachCursor(events []*Event, lookahead int, fn func(cur *eventCursor) bool) {
+	reader := newCursorReader(&sliceSource{events: events}, lookahead)
+
+	for {
+		cur, err := reader.next()
+		if err != nil || !fn(cur) {
+			return
+		}
+	}
+}
+
+// isNearest tells whether the group of the cursor is the closest one
+// to `t`, preferring the earliest group in case of a tie.
+func (cur *eventCursor) isNearest(t Time) bool {
+	time := cur.event.Time
+
+	if time < t {
+		return !cur.hasNext ||
+			(cur.nextTime >= t && cur.nextTime-t >= t-time)
+	}
+
+	return !cur.hasPrev ||
+		(cur.prevTime < t && t-cur.prevTime > time-t)
+}
+
+// matchesStart tells whether the event under the cursor is the one
+// that a range starting at `p` begins with.
+func (p Position) matchesStart(cur *eventCursor, policy SnapPolicy) bool {
+	switch p.Kind {
+	case PositionIndex:
+		return p.matchesIndex(cur)
+	case PositionMarker:
+		return p.matchesMarker(cur)
+	}
+
+	if !cur.firstOfGroup {
+		return false
+	}
+
+	time := cur.event.Time
+
+	switch policy {
+	case SnapNearest:
+		return cur.isNearest(p.Time)
+	case SnapEnclose:
+		return (time <= p.Time && (!cur.hasNext || cur.nextTime > p.Time)) ||
+			(time > p.Time && !cur.hasPrev)
+	case SnapWithin:
+		return time >= p.Time && (!cur.hasPrev || cur.prevTime < p.Time)
+	default:
+		return time == p.Time
+	}
+}
+
+// matchesEnd tells whether the event under the cursor is the one
+// that a range ending at `p` finishes with.
+func (p Position) matchesEnd(cur *eventCursor, policy SnapPolicy) bool {
+	switch p.Kind {
+	case PositionIndex:
+		return p.matchesIndex(cur)
+	case PositionMarker:
+		return p.matchesMarker(cur)
+	}
+
+	if !cur.lastOfGroup {
+		return false
+	}
+
+	time := cur.event.Time
+
+	switch policy {
+	case SnapNearest:
+		return cur.isNearest(p.Time)
+	case SnapEnclose:
+		return (time >= p.Time && (!cur.hasPrev || cur.prevTime < p.Time)) ||
+			(time < p.Time && !cur.hasNext)
+	case SnapWithin:
+		return time <= p.Time && (!cur.hasNext || cur.nextTime > p.Time)
+	default:
+		return time == p.Time
+	}
+}
+
+// matchesIndex tells whether the event under the cursor is the one
+// that an index position refers to.
+func (p Position) matchesIndex(cur *eventCursor) bool {
+	if p.Index < 0 {
+		return cur.remaining == -p.Index-1
+	}
+
+	return cur.idx == p.Index
+}
+
+// matchesMarker tells whether the event under the cursor is the marker
+// that a marker position refers to.
+func (p Position) matchesMarker(cur *eventCursor) bool {
+	return cur.event.Type == "m" && cur.event.Data == p.Label
+}
+
+// lookahead is the number of events that must be read ahead of the
+// current one to resolve the range.
+func (r Range) lookahead() (n int) {
+	for _, p := range []Position{r.From, r.To} {
+		if p.Kind == PositionIndex && p.Index < -n {
+			n = -p.Index
+		}
+	}
+
+	return
+}
+
+// ResolveRange finds the indexes of the first and last events of the
+// event stream that the range `r` refers to, snapping timestamps to
+// events according to `policy`.
+func ResolveRange(c *Cast, r Range, policy SnapPolicy) (fromIdx, toIdx int, err error) {
+	if c == nil {
+		err = errors.Errorf("a cast must be specified")
+		return
+	}
+
+	if len(c.EventStream) == 0 {
+		err = errors.Errorf(
+			"a cast with non-empty event stream must be supplied")
+		return
+	}
+
+	err = r.Validate()
+	if err != nil {
+		return
+	}
+
+	fromIdx = -1
+	toIdx = -1
+
+	forEachCursor(c.EventStream, r.lookahead(), func(cur *eventCursor) bool {
+		if fromIdx == -1 && r.From.matchesStart(cur, policy) {
+			fromIdx = cur.idx
+		}
+
+		if toIdx == -1 && r.To.matchesEnd(cur, policy) {
+			toIdx = cur.idx
+		}
+
+		return fromIdx == -1 || toIdx == -1
+	})
+
+	if fromIdx == -1 {
+		err = errors.Errorf("couldn't find initial frame")
+		return
+	}
+
+	if toIdx == -1 {
+		err = errors.Errorf("couldn't find final frame")
+		return
+	}
+
+	if fromIdx > toIdx {
+		err = errors.Errorf(
+			"initial frame (%s) comes after final frame (%s)",
+			r.From, r.To)
+		return
+	}
+
+	return
+}
+
+// ResolveTime finds the timestamp that the position `p` refers to: the
+// time itself, the time of the event at an index or the time of a
+// marker.
+func ResolveTime(c *Cast, p Position) (t Time, err error) {
+	if c == nil {
+		err = errors.Errorf("a cast must be specified")
+		return
+	}
+
+	if p.Kind == PositionTime {
+		t = p.Time
+		return
+	}
+
+	found := false
+
+	forEachCursor(c.EventStream, Range{From: p}.lookahead(), func(cur *eventCursor) bool {
+		if p.matchesStart(cur, SnapExact) {
+			t = cur.event.Time
+			found = true
+		}
+
+		return !found
+	})
+
+	if !found {
+		err = errors.Errorf("couldn't find an event at %s", p)
+		return
+	}
+
+	return
+}
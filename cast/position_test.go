@@ -0,0 +1,290 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestParsePosition(t *testing.T) {
+	t.Run("Valid inputs", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			expected cast.Position
+		}{
+			{"12.5", cast.TimeAt(cast.Seconds(12.5))},
+			{"0", cast.TimeAt(cast.Seconds(0))},
+			{"1:23.5", cast.TimeAt(cast.Seconds(83.5))},
+			{"01:02:03", cast.TimeAt(cast.Seconds(3723))},
+			{"1m23s", cast.TimeAt(cast.Seconds(83))},
+			{"500ms", cast.TimeAt(cast.Seconds(0.5))},
+			{"#12", cast.EventAt(12)},
+			{"#-1", cast.EventAt(-1)},
+			{"@intro", cast.MarkerAt("intro")},
+			{" @two words ", cast.MarkerAt("two words")},
+		}
+
+		for _, test := range tests {
+			t.Run(test.input, func(t *testing.T) {
+				res, err := cast.ParsePosition(test.input)
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, res)
+			})
+		}
+	})
+
+	t.Run("Invalid inputs", func(t *testing.T) {
+		tests := []string{
+			"", "abc", "-1", "#", "#a", "@", "1:60", "1:2:3:4", "1:-2", "1x", "nan",
+		}
+
+		for _, test := range tests {
+			t.Run(test, func(t *testing.T) {
+				_, err := cast.ParsePosition(test)
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("Round trips through String", func(t *testing.T) {
+		for _, p := range []cast.Position{cast.TimeAt(cast.Seconds(1.25)), cast.EventAt(-3), cast.MarkerAt("end")} {
+			res, err := cast.ParsePosition(p.String())
+			assert.NoError(t, err)
+			assert.Equal(t, p, res)
+		}
+	})
+}
+
+func TestParseSnapPolicy(t *testing.T) {
+	for _, policy := range []cast.SnapPolicy{
+		cast.SnapExact, cast.SnapNearest, cast.SnapEnclose, cast.SnapWithin,
+	} {
+		res, err := cast.ParseSnapPolicy(policy.String())
+		assert.NoError(t, err)
+		assert.Equal(t, policy, res)
+	}
+
+	_, err := cast.ParseSnapPolicy("closest")
+	assert.Error(t, err)
+}
+
+func TestResolveRange(t *testing.T) {
+	data := &cast.Cast{
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1)},
+			{Time: cast.Seconds(2)},
+			{Time: cast.Seconds(2)},
+			{Time: cast.Seconds(4), Type: "m", Data: "here"},
+			{Time: cast.Seconds(8)},
+		},
+	}
+
+	t.Run("Parameter validation", func(t *testing.T) {
+		_, _, err := cast.ResolveRange(nil, cast.Range{}, cast.SnapExact)
+		assert.Error(t, err)
+
+		_, _, err = cast.ResolveRange(&cast.Cast{}, cast.Range{}, cast.SnapExact)
+		assert.Error(t, err)
+
+		_, _, err = cast.ResolveRange(data, cast.Range{
+			From: cast.TimeAt(cast.Seconds(3)), To: cast.TimeAt(cast.Seconds(2)),
+		}, cast.SnapNearest)
+		assert.Error(t, err)
+	})
+
+	tests := []struct {
+		name           string
+		from, to       cast.Position
+		policy         cast.SnapPolicy
+		fromIdx, toIdx int
+		fails          bool
+	}{
+		{"exact match", cast.TimeAt(cast.Seconds(1)), cast.TimeAt(cast.Seconds(4)), cast.SnapExact, 0, 3, false},
+		{"exact match groups equal times", cast.TimeAt(cast.Seconds(2)), cast.TimeAt(cast.Seconds(2)), cast.SnapExact, 1, 2, false},
+		{"exact mismatch", cast.TimeAt(cast.Seconds(1.5)), cast.TimeAt(cast.Seconds(4)), cast.SnapExact, 0, 0, true},
+		{"nearest", cast.TimeAt(cast.Seconds(1.4)), cast.TimeAt(cast.Seconds(5.9)), cast.SnapNearest, 0, 3, false},
+		{"nearest prefers earliest on ties", cast.TimeAt(cast.Seconds(3)), cast.TimeAt(cast.Seconds(6)), cast.SnapNearest, 1, 3, false},
+		{"nearest past the end", cast.TimeAt(cast.Seconds(10)), cast.TimeAt(cast.Seconds(20)), cast.SnapNearest, 4, 4, false},
+		{"enclose", cast.TimeAt(cast.Seconds(1.5)), cast.TimeAt(cast.Seconds(3)), cast.SnapEnclose, 0, 3, false},
+		{"enclose before the start", cast.TimeAt(cast.Seconds(0)), cast.TimeAt(cast.Seconds(9)), cast.SnapEnclose, 0, 4, false},
+		{"within", cast.TimeAt(cast.Seconds(1.5)), cast.TimeAt(cast.Seconds(7)), cast.SnapWithin, 1, 3, false},
+		{"within an empty range", cast.TimeAt(cast.Seconds(2.5)), cast.TimeAt(cast.Seconds(3)), cast.SnapWithin, 0, 0, true},
+		{"indexes", cast.EventAt(1), cast.EventAt(-1), cast.SnapExact, 1, 4, false},
+		{"mixed", cast.EventAt(2), cast.TimeAt(cast.Seconds(4)), cast.SnapExact, 2, 3, false},
+		{"index out of bounds", cast.EventAt(1), cast.EventAt(5), cast.SnapExact, 0, 0, true},
+		{"markers", cast.MarkerAt("here"), cast.EventAt(-1), cast.SnapExact, 3, 4, false},
+		{"unknown marker", cast.EventAt(0), cast.MarkerAt("there"), cast.SnapExact, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fromIdx, toIdx, err := cast.ResolveRange(data, cast.Range{
+				From: test.from, To: test.to,
+			}, test.policy)
+			if test.fails {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.fromIdx, fromIdx)
+			assert.Equal(t, test.toIdx, toIdx)
+		})
+	}
+}
+
+func TestResolveTime(t *testing.T) {
+	data := &cast.Cast{
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o"},
+			{Time: cast.Seconds(2), Type: "m", Data: "here"},
+			{Time: cast.Seconds(3), Type: "m", Data: "here"},
+		},
+	}
+
+	tests := []struct {
+		position cast.Position
+		expected cast.Time
+		fails    bool
+	}{
+		{cast.TimeAt(cast.Seconds(2.5)), cast.Seconds(2.5), false},
+		{cast.EventAt(0), cast.Seconds(1), false},
+		{cast.EventAt(-1), cast.Seconds(3), false},
+		{cast.EventAt(3), 0, true},
+		{cast.MarkerAt("here"), cast.Seconds(2), false},
+		{cast.MarkerAt("there"), 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.position.String(), func(t *testing.T) {
+			res, err := cast.ResolveTime(data, test.position)
+			if test.fails {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestCutRange(t *testing.T) {
+	data := &cast.Cast{
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Data: "a"},
+			{Time: cast.Seconds(1.9), Data: "b"},
+			{Time: cast.Seconds(3.1), Data: "c"},
+			{Time: cast.Seconds(4), Data: "d"},
+		},
+	}
+
+	err := cast.CutRange(data, cast.Range{
+		From: cast.TimeAt(cast.Seconds(2)), To: cast.TimeAt(cast.Seconds(3)),
+	}, cast.SnapNearest)
+	assert.NoError(t, err)
+
+	assert.Len(t, data.EventStream, 2)
+	assert.Equal(t, "a", data.EventStream[0].Data)
+	assert.Equal(t, "d", data.EventStream[1].Data)
+	assert.Equal(t, cast.Seconds(1.9), data.EventStream[1].Time)
+}
+
+func TestSpeedRange(t *testing.T) {
+	data := setup()
+
+	err := cast.SpeedRange(data, 2, cast.Range{
+		From: cast.TimeAt(cast.Seconds(1.2)), To: cast.EventAt(2),
+	}, cast.SnapNearest)
+	assert.NoError(t, err)
+
+	assert.Equal(t, cast.Seconds(1), data.EventStream[0].Time)
+	assert.Equal(t, cast.Seconds(3), data.EventStream[1].Time)
+	assert.Equal(t, cast.Seconds(5), data.EventStream[2].Time)
+	assert.Equal(t, cast.Seconds(6), data.EventStream[3].Time)
+}
+
+func TestParseRange(t *testing.T) {
+	t.Run("Parses both ends", func(t *testing.T) {
+		r, err := cast.ParseRange("1:05, @intro")
+		assert.NoError(t, err)
+		assert.Equal(t, cast.Range{From: cast.TimeAt(cast.Seconds(65)), To: cast.MarkerAt("intro")}, r)
+		assert.Equal(t, "65.000000,@intro", r.String())
+	})
+
+	for _, input := range []string{"1", "1,2,3", "x,2", "3,2"} {
+		t.Run("With invalid range "+input, func(t *testing.T) {
+			_, err := cast.ParseRange(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCutRanges(t *testing.T) {
+	newCast := func() *cast.Cast {
+		return &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Data: "a"},
+				{Time: cast.Seconds(2), Data: "b"},
+				{Time: cast.Seconds(3), Data: "c"},
+				{Time: cast.Seconds(5), Data: "d"},
+				{Time: cast.Seconds(6), Data: "e"},
+				{Time: cast.Seconds(8), Data: "f"},
+			},
+		}
+	}
+
+	t.Run("Removes ranges of the original timeline", func(t *testing.T) {
+		data := newCast()
+
+		err := cast.CutRanges(data, []cast.Range{
+			{From: cast.TimeAt(cast.Seconds(5)), To: cast.TimeAt(cast.Seconds(6))},
+			{From: cast.TimeAt(cast.Seconds(2)), To: cast.TimeAt(cast.Seconds(2))},
+		}, cast.SnapExact)
+		assert.NoError(t, err)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Data: "a"},
+			{Time: cast.Seconds(2), Data: "c"},
+			{Time: cast.Seconds(4), Data: "f"},
+		}, data.EventStream)
+	})
+
+	t.Run("With overlapping ranges", func(t *testing.T) {
+		err := cast.CutRanges(newCast(), []cast.Range{
+			{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(3))},
+			{From: cast.TimeAt(cast.Seconds(3)), To: cast.TimeAt(cast.Seconds(5))},
+		}, cast.SnapExact)
+		assert.EqualError(t, err, "ranges 1.000000,3.000000 and 3.000000,5.000000 overlap")
+	})
+
+	t.Run("Without ranges", func(t *testing.T) {
+		assert.Error(t, cast.CutRanges(newCast(), nil, cast.SnapExact))
+	})
+}
+
+func TestSpeedRanges(t *testing.T) {
+	t.Run("Speeds ranges of the original timeline", func(t *testing.T) {
+		data := setup()
+
+		err := cast.SpeedRanges(data, []cast.FactorRange{
+			{Range: cast.Range{From: cast.TimeAt(cast.Seconds(3)), To: cast.TimeAt(cast.Seconds(4))}, Factor: 0.5},
+			{Range: cast.Range{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(3))}, Factor: 2},
+		}, cast.SnapExact)
+		assert.NoError(t, err)
+
+		assert.Equal(t, cast.Seconds(1), data.EventStream[0].Time)
+		assert.Equal(t, cast.Seconds(3), data.EventStream[1].Time)
+		assert.Equal(t, cast.Seconds(5), data.EventStream[2].Time)
+		assert.Equal(t, cast.Seconds(5.5), data.EventStream[3].Time)
+	})
+
+	t.Run("With overlapping ranges", func(t *testing.T) {
+		err := cast.SpeedRanges(setup(), []cast.FactorRange{
+			{Range: cast.Range{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(3))}, Factor: 2},
+			{Range: cast.Range{From: cast.TimeAt(cast.Seconds(2)), To: cast.TimeAt(cast.Seconds(4))}, Factor: 2},
+		}, cast.SnapExact)
+		assert.Error(t, err)
+	})
+}
@@ -10,21 +10,87 @@ import (
 // range are trimmed to `from`.
 type QuantizeRange struct {
 	// From indicates the start of the range
-	From float64
+	From Time
 	// To indicates the end of the range
-	To float64
+	To Time
 }
 
 // InRange verifies whether a given value lies within the quantization
 // range.
-func (q *QuantizeRange) InRange(value float64) bool {
+func (q *QuantizeRange) InRange(value Time) bool {
 	return value >= q.From && value < q.To
 }
 
-// Overlaps verifies whether a given range (`another`) overlaps with
-// this range.
+// RangeOverlaps verifies whether a given range (`another`) overlaps
+// with this range, that is, whether a value could lie in both of them.
 func (q *QuantizeRange) RangeOverlaps(another QuantizeRange) bool {
-	return q.InRange(another.From) || q.InRange(another.To)
+	return q.From < another.To && another.From < q.To
+}
+
+// String returns the range as `from,to`, or just `from` when it's
+// unbounded.
+func (q QuantizeRange) String() string {
+	if q.To == MaxTime {
+		return q.From.String()
+	}
+
+	return q.From.String() + "," + q.To.String()
+}
+
+// ValidateQuantizeRanges makes sure that there's at least one range,
+// that every range is well formed and that no two ranges overlap, so
+// that a delay can only be reduced by a single range.
+func ValidateQuantizeRanges(ranges []QuantizeRange) error {
+	if len(ranges) == 0 {
+		return errors.Errorf("at least one quantization range must be specified")
+	}
+
+	for idx, q := range ranges {
+		if q.From < 0 {
+			return errors.Errorf("quantization range %s must not start before 0", q)
+		}
+
+		if q.To <= q.From {
+			return errors.Errorf("quantization range %s must end after it starts", q)
+		}
+
+		for _, previous := range ranges[:idx] {
+			if previous.RangeOverlaps(q) {
+				return errors.Errorf("quantization ranges %s and %s overlap", previous, q)
+			}
+		}
+	}
+
+	return nil
+}
+
+// QuantizeScope limits which delays of a cast get quantized.
+type QuantizeScope struct {
+	// Span, unless nil, limits quantization to the delays between the
+	// events it delimits (see `ResolveRange`), snapped according to
+	// `Policy`. The events that come after it are shifted accordingly.
+	Span   *Range
+	Policy SnapPolicy
+
+	// Before, unless empty, limits quantization to the delays that end
+	// with an event of one of the given types (e.g., `i` to tune the
+	// typing rhythm or `o` to tune the pauses before output).
+	Before []string
+}
+
+// covers tells whether the delay before `ev` is to be quantized.
+func (s *QuantizeScope) covers(ev *Event) bool {
+	if len(s.Before) == 0 {
+		return true
+	}
+
+	for _, typ := range s.Before {
+		if ev.Type == typ {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Quantize constraints a set of inputs that lie in a range to a single
@@ -54,7 +120,15 @@ func (q *QuantizeRange) RangeOverlaps(another QuantizeRange) bool {
 //  3. if it fits, reduce the delay to the maximum allowed (floor of
 //     the quantization range).
 //  4. adjust the rest of the event stream.
+//
+// The ranges must not overlap (see `ValidateQuantizeRanges`).
 func Quantize(c *Cast, ranges []QuantizeRange) (err error) {
+	return QuantizeScoped(c, ranges, QuantizeScope{})
+}
+
+// QuantizeScoped quantizes the delays of a cast like `Quantize`, only
+// touching the ones that `scope` covers.
+func QuantizeScoped(c *Cast, ranges []QuantizeRange, scope QuantizeScope) (err error) {
 	if c == nil {
 		err = errors.Errorf("cast must not be nil")
 		return
@@ -65,27 +139,30 @@ func Quantize(c *Cast, ranges []QuantizeRange) (err error) {
 		return
 	}
 
-	if len(ranges) == 0 {
-		err = errors.Errorf("at least one quantization range must be specified")
+	err = ValidateQuantizeRanges(ranges)
+	if err != nil {
 		return
 	}
 
 	var (
-		deltas = make([]float64, len(c.EventStream))
-		delta  float64
-		i      int
+		fromIdx = 0
+		toIdx   = len(c.EventStream) - 1
+		deltas  = make([]Time, len(c.EventStream))
+		delta   Time
+		i       int
 	)
 
+	if scope.Span != nil {
+		fromIdx, toIdx, err = ResolveRange(c, *scope.Span, scope.Policy)
+		if err != nil {
+			return
+		}
+	}
+
 	for i = 0; i < len(c.EventStream)-1; i++ {
 		delta = c.EventStream[i+1].Time - c.EventStream[i].Time
-
-		for _, qRange := range ranges {
-			if !qRange.InRange(delta) {
-				continue
-			}
-
-			delta = qRange.From
-			break
+		if i >= fromIdx && i < toIdx && scope.covers(c.EventStream[i+1]) {
+			delta = quantizeDelta(delta, ranges)
 		}
 
 		deltas[i] = delta
@@ -97,3 +174,15 @@ func Quantize(c *Cast, ranges []QuantizeRange) (err error) {
 
 	return
 }
+
+// quantizeDelta reduces a delay to the lower bound of the first range
+// that it lies in.
+func quantizeDelta(delta Time, ranges []QuantizeRange) Time {
+	for _, qRange := range ranges {
+		if qRange.InRange(delta) {
+			return qRange.From
+		}
+	}
+
+	return delta
+}
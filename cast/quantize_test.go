@@ -47,56 +47,70 @@ func TestQuantize(t *testing.T) {
 
 	t.Run("RangeOverlaps", func(t *testing.T) {
 		qRange := &cast.QuantizeRange{
-			From: 1,
-			To:   2,
+			From: cast.Seconds(1),
+			To:   cast.Seconds(2),
 		}
 
 		t.Run("Doesn't overlap if no in another range", func(t *testing.T) {
 			assert.False(t, qRange.RangeOverlaps(cast.QuantizeRange{
-				From: 30,
-				To:   40,
+				From: cast.Seconds(30),
+				To:   cast.Seconds(40),
 			}))
 		})
 
 		t.Run("Overlaps if from in another range", func(t *testing.T) {
 			assert.True(t, qRange.RangeOverlaps(cast.QuantizeRange{
-				From: 1.5,
-				To:   3,
+				From: cast.Seconds(1.5),
+				To:   cast.Seconds(3),
 			}))
 		})
 
 		t.Run("Overlaps if to in another range", func(t *testing.T) {
 			assert.True(t, qRange.RangeOverlaps(cast.QuantizeRange{
-				From: 0.9,
-				To:   1.5,
+				From: cast.Seconds(0.9),
+				To:   cast.Seconds(1.5),
+			}))
+		})
+
+		t.Run("Overlaps if it encloses another range", func(t *testing.T) {
+			assert.True(t, qRange.RangeOverlaps(cast.QuantizeRange{
+				From: cast.Seconds(0.5),
+				To:   cast.Seconds(3),
+			}))
+		})
+
+		t.Run("Doesn't overlap if it ends where another range starts", func(t *testing.T) {
+			assert.False(t, qRange.RangeOverlaps(cast.QuantizeRange{
+				From: cast.Seconds(2),
+				To:   cast.MaxTime,
 			}))
 		})
 	})
 
 	t.Run("InRange", func(t *testing.T) {
 		qRange := &cast.QuantizeRange{
-			From: 1,
-			To:   2,
+			From: cast.Seconds(1),
+			To:   cast.Seconds(2),
 		}
 
 		t.Run("In range if `from <= x < to`", func(t *testing.T) {
-			assert.True(t, qRange.InRange(1.5))
+			assert.True(t, qRange.InRange(cast.Seconds(1.5)))
 		})
 
 		t.Run("In range if `x == from`", func(t *testing.T) {
-			assert.True(t, qRange.InRange(1))
+			assert.True(t, qRange.InRange(cast.Seconds(1)))
 		})
 
 		t.Run("Not in range if `x == to`", func(t *testing.T) {
-			assert.False(t, qRange.InRange(2))
+			assert.False(t, qRange.InRange(cast.Seconds(2)))
 		})
 
 		t.Run("Not in range if `x > to`", func(t *testing.T) {
-			assert.False(t, qRange.InRange(2.1))
+			assert.False(t, qRange.InRange(cast.Seconds(2.1)))
 		})
 
 		t.Run("Not in range if `x < from`", func(t *testing.T) {
-			assert.False(t, qRange.InRange(0.9))
+			assert.False(t, qRange.InRange(cast.Seconds(0.9)))
 		})
 	})
 
@@ -109,12 +123,12 @@ func TestQuantize(t *testing.T) {
 		)
 
 		setup := func() {
-			event1 = &cast.Event{Time: 1}
-			event2 = &cast.Event{Time: 2}
-			event5 = &cast.Event{Time: 5}
-			event9 = &cast.Event{Time: 9}
-			event10 = &cast.Event{Time: 10}
-			event11 = &cast.Event{Time: 11}
+			event1 = &cast.Event{Time: cast.Seconds(1)}
+			event2 = &cast.Event{Time: cast.Seconds(2)}
+			event5 = &cast.Event{Time: cast.Seconds(5)}
+			event9 = &cast.Event{Time: cast.Seconds(9)}
+			event10 = &cast.Event{Time: cast.Seconds(10)}
+			event11 = &cast.Event{Time: cast.Seconds(11)}
 
 			data = &cast.Cast{
 				EventStream: []*cast.Event{
@@ -130,16 +144,80 @@ func TestQuantize(t *testing.T) {
 
 		t.Run("Cuts down delays with a single range", func(t *testing.T) {
 			setup()
-			ranges := []cast.QuantizeRange{{2, 6}}
+			ranges := []cast.QuantizeRange{{cast.Seconds(2), cast.Seconds(6)}}
 			err = cast.Quantize(data, ranges)
 			assert.NoError(t, err)
 
-			assert.Equal(t, float64(1), event1.Time)
-			assert.Equal(t, float64(2), event2.Time)
-			assert.Equal(t, float64(4), event5.Time)
-			assert.Equal(t, float64(6), event9.Time)
-			assert.Equal(t, float64(7), event10.Time)
-			assert.Equal(t, float64(8), event11.Time)
+			assert.Equal(t, cast.Seconds(1), event1.Time)
+			assert.Equal(t, cast.Seconds(2), event2.Time)
+			assert.Equal(t, cast.Seconds(4), event5.Time)
+			assert.Equal(t, cast.Seconds(6), event9.Time)
+			assert.Equal(t, cast.Seconds(7), event10.Time)
+			assert.Equal(t, cast.Seconds(8), event11.Time)
+		})
+
+		t.Run("Fails with overlapping ranges", func(t *testing.T) {
+			setup()
+			err = cast.Quantize(data, []cast.QuantizeRange{
+				{From: cast.Seconds(1), To: cast.Seconds(3)},
+				{From: cast.Seconds(2), To: cast.MaxTime},
+			})
+			assert.EqualError(t, err, "quantization ranges 1.000000,3.000000 and 2.000000 overlap")
+		})
+	})
+}
+
+func TestQuantizeScoped(t *testing.T) {
+	var (
+		ranges = []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.MaxTime}}
+		data   *cast.Cast
+	)
+
+	setup := func() {
+		data = &cast.Cast{
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o"},
+				{Time: cast.Seconds(3), Type: "i"},
+				{Time: cast.Seconds(6), Type: "o"},
+				{Time: cast.Seconds(10), Type: "m", Data: "here"},
+				{Time: cast.Seconds(15), Type: "i"},
+			},
+		}
+	}
+
+	times := func() (res []cast.Time) {
+		for _, ev := range data.EventStream {
+			res = append(res, ev.Time)
+		}
+
+		return
+	}
+
+	t.Run("Only quantizes the delays within the span", func(t *testing.T) {
+		setup()
+		err := cast.QuantizeScoped(data, ranges, cast.QuantizeScope{
+			Span: &cast.Range{From: cast.EventAt(1), To: cast.MarkerAt("here")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(3), cast.Seconds(4), cast.Seconds(5), cast.Seconds(10),
+		}, times())
+	})
+
+	t.Run("Only quantizes the delays before events of some types", func(t *testing.T) {
+		setup()
+		err := cast.QuantizeScoped(data, ranges, cast.QuantizeScope{Before: []string{"i"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(2), cast.Seconds(5), cast.Seconds(9), cast.Seconds(10),
+		}, times())
+	})
+
+	t.Run("Fails if the span can't be found", func(t *testing.T) {
+		setup()
+		err := cast.QuantizeScoped(data, ranges, cast.QuantizeScope{
+			Span: &cast.Range{From: cast.MarkerAt("there"), To: cast.EventAt(-1)},
 		})
+		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,163 @@
+package cast
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Detector finds secrets to redact.
+type Detector struct {
+	Name string
+
+	// Pattern matches the secrets. If it has a group named `secret`,
+	// only the text of that group is masked (e.g. the value of a
+	// `password=...` pair); otherwise the whole match is.
+	Pattern *regexp.Regexp
+}
+
+// BuiltinDetectors returns detectors for common kinds of secrets: AWS
+// keys, GitHub and GitLab tokens, JSON web tokens, private key blocks
+// and `password=...`-like assignments.
+func BuiltinDetectors() []Detector {
+	return []Detector{
+		{"aws-access-key", regexp.MustCompile(
+			`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA)[0-9A-Z]{16}\b`)},
+		{"aws-secret-key", regexp.MustCompile(
+			`(?i)aws_?(?:secret_?(?:access_?)?key)["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})\b`)},
+		{"github-token", regexp.MustCompile(
+			`\b(?:(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+		{"gitlab-token", regexp.MustCompile(
+			`\b(?:glpat|gldt|glrt|glptt|glsoat)-[A-Za-z0-9_-]{20,}`)},
+		{"jwt", regexp.MustCompile(
+			`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+		{"private-key", regexp.MustCompile(
+			`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?P<secret>[\s\S]*?)-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----`)},
+		{"password", regexp.MustCompile(
+			`(?i)\b(?:password|passwd|pwd|secret|token|api_?key)["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&;]+)`)},
+	}
+}
+
+// Redaction is a secret masked by `Redact`.
+type Redaction struct {
+	Detector string
+
+	// Type is the type of the events holding the secret (`o` or `i`).
+	Type string
+
+	// Index and Time identify the event where the secret starts.
+	Index int
+	Time  Time
+
+	// Length is the number of characters masked.
+	Length int
+}
+
+// redactedTypes are the types of the events whose data gets redacted.
+var redactedTypes = []string{"o", "i"}
+
+// Redact replaces the secrets found by `detectors` in the output (`o`)
+// and input (`i`) events of a cast with `mask`, one mask per character
+// so that the layout of the screen is kept.
+//
+// The data of the events of each type is searched as a whole, so that
+// secrets split across events are found; escape sequences are left out
+// of the search and never masked, and neither are control characters
+// (line breaks within a private key block stay in place).
+func Redact(c *Cast, detectors []Detector, mask rune) ([]Redaction, error) {
+	if c == nil {
+		return nil, errors.Errorf("cast must not be nil")
+	}
+
+	if mask < 0x20 || mask == 0x7f || !utf8.ValidRune(mask) {
+		return nil, errors.Errorf("mask must be a printable character")
+	}
+
+	for _, detector := range detectors {
+		if detector.Pattern == nil {
+			return nil, errors.Errorf("detector %q has no pattern", detector.Name)
+		}
+	}
+
+	var redactions []Redaction
+
+	for _, evType := range redactedTypes {
+		var (
+			stream = newTextStream(c.EventStream, evType)
+			masked = make([]bool, len(stream.text))
+		)
+
+		for _, detector := range detectors {
+			group := detector.Pattern.SubexpIndex("secret")
+
+			for _, loc := range detector.Pattern.FindAllStringSubmatchIndex(stream.text, -1) {
+				from, to := loc[0], loc[1]
+				if group > 0 {
+					from, to = loc[2*group], loc[2*group+1]
+				}
+
+				count := maskText(stream.text, masked, from, to)
+				if count == 0 {
+					continue
+				}
+
+				first := stream.events[from]
+				redactions = append(redactions, Redaction{
+					Detector: detector.Name,
+					Type:     evType,
+					Index:    first,
+					Time:     c.EventStream[first].Time,
+					Length:   count,
+				})
+			}
+		}
+
+		stream.applyEdits(c.EventStream, maskEdits(stream.text, masked, mask))
+	}
+
+	sort.SliceStable(redactions, func(i, j int) bool {
+		return redactions[i].Index < redactions[j].Index
+	})
+
+	return redactions, nil
+}
+
+// maskText marks the printable characters of `text[from:to]` that
+// weren't masked yet, returning how many of them there are.
+func maskText(text string, masked []bool, from, to int) (count int) {
+	if from < 0 || from >= to {
+		return 0
+	}
+
+	for offset, r := range text[from:to] {
+		if r < 0x20 || r == 0x7f || masked[from+offset] {
+			continue
+		}
+
+		masked[from+offset] = true
+		count++
+	}
+
+	return
+}
+
+// maskEdits turns the masked characters of a text stream into edits,
+// masking each of them with as many mask characters as the columns it
+// takes so that the rest of the line stays in place.
+func maskEdits(text string, masked []bool, mask rune) (edits []textEdit) {
+	for pos, r := range text {
+		if masked[pos] {
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			edits = append(edits, textEdit{
+				from: pos,
+				to:   pos + size,
+				text: strings.Repeat(string(mask), RuneWidth(r)),
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,44 @@
+package cast
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// Replace replaces the text matched by `pattern` in the data of the
+// events of type `evType` (usually `o`) with `replacement`, in which
+// `$1` or `${name}` stand for the text of submatches (see
+// `regexp.Regexp.Expand`). It returns the number of replacements.
+//
+// The data of the events is searched as a whole, without escape
+// sequences, so that text split across events (or broken up by escape
+// sequences) is matched. The replacement text is spread over the events
+// that the matched text came from, which keep their timestamps.
+func Replace(c *Cast, evType string, pattern *regexp.Regexp, replacement string) (int, error) {
+	if c == nil {
+		return 0, errors.Errorf("cast must not be nil")
+	}
+
+	if pattern == nil {
+		return 0, errors.Errorf("pattern must not be nil")
+	}
+
+	var (
+		stream = newTextStream(c.EventStream, evType)
+		edits  []textEdit
+	)
+
+	for _, loc := range pattern.FindAllStringSubmatchIndex(stream.text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+
+		text := pattern.ExpandString(nil, replacement, stream.text, loc)
+		edits = append(edits, textEdit{from: loc[0], to: loc[1], text: string(text)})
+	}
+
+	stream.applyEdits(c.EventStream, edits)
+
+	return len(edits), nil
+}
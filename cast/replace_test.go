@@ -0,0 +1,83 @@
+package cast_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestReplace(t *testing.T) {
+	replace := func(pattern, replacement string, events ...*cast.Event) ([]*cast.Event, int) {
+		c := &cast.Cast{EventStream: events}
+
+		count, err := cast.Replace(c, "o", regexp.MustCompile(pattern), replacement)
+		assert.NoError(t, err)
+
+		return c.EventStream, count
+	}
+
+	t.Run("With nil cast", func(t *testing.T) {
+		_, err := cast.Replace(nil, "o", regexp.MustCompile("a"), "b")
+		assert.Error(t, err)
+	})
+
+	t.Run("With nil pattern", func(t *testing.T) {
+		_, err := cast.Replace(&cast.Cast{}, "o", nil, "b")
+		assert.Error(t, err)
+	})
+
+	t.Run("Replaces text within events", func(t *testing.T) {
+		events, count := replace("host", "server",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "host1 host2"},
+			&cast.Event{Time: cast.Seconds(2), Type: "i", Data: "host"},
+		)
+
+		assert.Equal(t, 2, count)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "server1 server2"},
+			{Time: cast.Seconds(2), Type: "i", Data: "host"},
+		}, events)
+	})
+
+	t.Run("Spreads the replacement over the events it spans", func(t *testing.T) {
+		events, count := replace("build-07", "demo",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "$ b"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "u"},
+			&cast.Event{Time: cast.Seconds(3), Type: "i", Data: "x"},
+			&cast.Event{Time: cast.Seconds(4), Type: "o", Data: "i"},
+			&cast.Event{Time: cast.Seconds(5), Type: "o", Data: "ld-07\r\n"},
+		)
+
+		assert.Equal(t, 1, count)
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "$ "},
+			{Time: cast.Seconds(2), Type: "o", Data: "d"},
+			{Time: cast.Seconds(3), Type: "i", Data: "x"},
+			{Time: cast.Seconds(4), Type: "o", Data: ""},
+			{Time: cast.Seconds(5), Type: "o", Data: "emo\r\n"},
+		}, events)
+	})
+
+	t.Run("Skips escape sequences", func(t *testing.T) {
+		events, _ := replace("v1.2", "v2.0",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "\x1b[1mv1\x1b"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "[0m.2é"},
+		)
+
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "\x1b[1mv2\x1b"},
+			{Time: cast.Seconds(2), Type: "o", Data: "[0m.0é"},
+		}, events)
+	})
+
+	t.Run("Expands submatches", func(t *testing.T) {
+		events, _ := replace(`user=(\w+)`, "user=<$1>",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "user=alice"},
+		)
+
+		assert.Equal(t, "user=<alice>", events[0].Data)
+	})
+}
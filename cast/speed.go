@@ -6,7 +6,33 @@ import (
 
 // Speed updates the cast speed by multiplying all of the
 // timestamps in a given range by a given factor.
-func Speed(c *Cast, factor, from, to float64) error {
+func Speed(c *Cast, factor float64, from, to Time) error {
+	if from >= to {
+		return errors.Errorf("`from` must not be greater or equal than `to`")
+	}
+
+	return SpeedRange(c, factor, Range{From: TimeAt(from), To: TimeAt(to)}, SnapExact)
+}
+
+// SpeedRange updates the cast speed by multiplying the delays between
+// the events delimited by `r` by a given factor, snapping times to
+// events according to `policy` (see `ResolveRange`).
+func SpeedRange(c *Cast, factor float64, r Range, policy SnapPolicy) error {
+	return SpeedRanges(c, []FactorRange{{Range: r, Factor: factor}}, policy)
+}
+
+// FactorRange is a range along with the factor that the delays between
+// its events get multiplied by.
+type FactorRange struct {
+	Range
+	Factor float64
+}
+
+// SpeedRanges updates the speed of several pieces of the cast at once
+// (see `SpeedRange`). The ranges all refer to the original timeline and
+// must not overlap, although a range may start at the event where the
+// previous one ends.
+func SpeedRanges(c *Cast, ranges []FactorRange, policy SnapPolicy) error {
 	if c == nil {
 		return errors.Errorf("cast must not be nil")
 	}
@@ -15,48 +41,54 @@ func Speed(c *Cast, factor, from, to float64) error {
 		return errors.Errorf("event stream must be nonempty")
 	}
 
-	if factor > 10 || factor < 0.1 {
-		return errors.Errorf("factor must be within 0.1 and 10 range")
-	}
+	spans := make([]Range, len(ranges))
+	for idx, r := range ranges {
+		if r.Factor > 10 || r.Factor < 0.1 {
+			return errors.Errorf("factor must be within 0.1 and 10 range")
+		}
 
-	if from >= to {
-		return errors.Errorf("`from` must not be greater or equal than `to`")
+		spans[idx] = r.Range
 	}
 
-	fromIdx := -1
-	toIdx := -1
+	resolved, err := resolveRanges(c, spans, policy, true)
+	if err != nil {
+		return err
+	}
 
-	for idx, ev := range c.EventStream {
-		if ev.Time == from {
-			fromIdx = idx
-		}
+	for _, r := range resolved {
+		if r.fromIdx >= r.toIdx {
+			err = errors.Errorf("`from` must not be greater or equal than `to`")
+			if len(ranges) > 1 {
+				err = errors.Wrapf(err, "range %s", r.Range)
+			}
 
-		if ev.Time == to {
-			toIdx = idx
+			return err
 		}
 	}
 
-	if fromIdx == -1 {
-		return errors.Errorf("couldn't find initial frame")
+	for _, r := range resolved {
+		speedEvents(c, ranges[r.idx].Factor, r.fromIdx, r.toIdx)
 	}
 
-	if toIdx == -1 {
-		return errors.Errorf("couldn't find final frame")
-	}
+	return nil
+}
 
+// speedEvents multiplies the delays between the events from `fromIdx`
+// to `toIdx` by `factor`, shifting the events that come after them.
+func speedEvents(c *Cast, factor float64, fromIdx, toIdx int) {
 	var (
 		i                int
 		k                int
-		delta            float64
-		newDelta         float64
-		accumulatedDelta float64
-		deltas           = make([]float64, toIdx-fromIdx)
+		delta            Time
+		newDelta         Time
+		accumulatedDelta Time
+		deltas           = make([]Time, toIdx-fromIdx)
 	)
 
 	k = 0
 	for i = fromIdx; i < toIdx; i++ {
 		delta = c.EventStream[i+1].Time - c.EventStream[i].Time
-		newDelta = delta * factor
+		newDelta = delta.Scale(factor)
 		accumulatedDelta += (newDelta - delta)
 
 		deltas[k] = newDelta
@@ -74,6 +106,4 @@ func Speed(c *Cast, factor, from, to float64) error {
 			remainingElem.Time += accumulatedDelta
 		}
 	}
-
-	return nil
 }
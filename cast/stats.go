@@ -0,0 +1,147 @@
+package cast
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// GapBounds are the lower bounds of the buckets of the idle gap
+// histogram of `Stats`.
+var GapBounds = []Time{
+	0,
+	100 * Millisecond,
+	250 * Millisecond,
+	500 * Millisecond,
+	1 * Second,
+	2 * Second,
+	5 * Second,
+	10 * Second,
+	30 * Second,
+}
+
+// GapBucket counts the gaps between events whose length is within
+// `[From, To)`.
+type GapBucket struct {
+	From Time `json:"from"`
+
+	// To is zero for the last bucket, which has no upper bound.
+	To Time `json:"to,omitempty"`
+
+	Count int `json:"count"`
+
+	// Total is the sum of the gaps of the bucket.
+	Total Time `json:"total"`
+}
+
+// Pause is a gap between two events.
+type Pause struct {
+	// Time is the timestamp of the beginning of the pause (the previous
+	// event, or the beginning of the recording).
+	Time Time `json:"time"`
+
+	// Index is the index of the event that ends the pause.
+	Index int `json:"index"`
+
+	Duration Time `json:"duration"`
+}
+
+// Stats summarizes the contents of a cast.
+type Stats struct {
+	Version uint8 `json:"version"`
+	Width   uint  `json:"width"`
+	Height  uint  `json:"height"`
+
+	// Duration is the timestamp of the last event.
+	Duration Time `json:"duration"`
+
+	Events int `json:"events"`
+
+	// EventTypes counts the events of each type (`o`, `i`, `r`, `m`...).
+	EventTypes map[string]int `json:"event_types"`
+
+	// OutputBytes is the size of the data of the output (`o`) events.
+	OutputBytes int `json:"output_bytes"`
+
+	Resizes int `json:"resizes"`
+
+	// Gaps is the histogram of the gaps between events (including the
+	// one before the first event), bucketed according to `GapBounds`.
+	Gaps []GapBucket `json:"gaps"`
+
+	// LongestPauses lists the longest gaps between events, longest
+	// first.
+	LongestPauses []Pause `json:"longest_pauses"`
+}
+
+// ComputeStats summarizes a cast, listing its `top` longest pauses.
+func ComputeStats(c *Cast, top int) (*Stats, error) {
+	if c == nil {
+		return nil, errors.Errorf("cast must not be nil")
+	}
+
+	if top < 0 {
+		return nil, errors.Errorf("number of pauses must not be negative")
+	}
+
+	stats := &Stats{
+		Version:       c.Header.Version,
+		Width:         c.Header.Width,
+		Height:        c.Header.Height,
+		Events:        len(c.EventStream),
+		EventTypes:    map[string]int{},
+		Gaps:          make([]GapBucket, len(GapBounds)),
+		LongestPauses: []Pause{},
+	}
+
+	for idx, from := range GapBounds {
+		stats.Gaps[idx].From = from
+		if idx+1 < len(GapBounds) {
+			stats.Gaps[idx].To = GapBounds[idx+1]
+		}
+	}
+
+	var (
+		pauses []Pause
+		last   Time
+	)
+
+	for idx, ev := range c.EventStream {
+		stats.EventTypes[ev.Type]++
+
+		switch ev.Type {
+		case "o":
+			stats.OutputBytes += len(ev.Data)
+		case "r":
+			stats.Resizes++
+		}
+
+		gap := ev.Time - last
+		bucket := sort.Search(len(GapBounds), func(i int) bool {
+			return GapBounds[i] > gap
+		}) - 1
+		if bucket < 0 {
+			bucket = 0
+		}
+
+		stats.Gaps[bucket].Count++
+		stats.Gaps[bucket].Total += gap
+
+		pauses = append(pauses, Pause{Time: last, Index: idx, Duration: gap})
+		last = ev.Time
+	}
+
+	stats.Duration = last
+
+	sort.SliceStable(pauses, func(i, j int) bool {
+		return pauses[i].Duration > pauses[j].Duration
+	})
+
+	if len(pauses) > top {
+		pauses = pauses[:top]
+	}
+
+	stats.LongestPauses = append(stats.LongestPauses, pauses...)
+
+	return stats, nil
+}
@@ -0,0 +1,82 @@
+package cast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestComputeStats(t *testing.T) {
+	t.Run("With nil cast", func(t *testing.T) {
+		_, err := cast.ComputeStats(nil, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("With a negative number of pauses", func(t *testing.T) {
+		_, err := cast.ComputeStats(&cast.Cast{}, -1)
+		assert.Error(t, err)
+	})
+
+	t.Run("With an empty event stream", func(t *testing.T) {
+		stats, err := cast.ComputeStats(&cast.Cast{}, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, cast.Time(0), stats.Duration)
+		assert.Empty(t, stats.LongestPauses)
+		assert.Len(t, stats.Gaps, len(cast.GapBounds))
+	})
+
+	data := &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 80, Height: 24},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(0.05), Type: "o", Data: "hello"},
+			{Time: cast.Seconds(0.3), Type: "i", Data: "x"},
+			{Time: cast.Seconds(2.3), Type: "o", Data: "x\r\n"},
+			{Time: cast.Seconds(2.3), Type: "r", Data: "100x30"},
+			{Time: cast.Seconds(42.3), Type: "m", Data: "end"},
+		},
+	}
+
+	stats, err := cast.ComputeStats(data, 2)
+	assert.NoError(t, err)
+
+	t.Run("Counts events", func(t *testing.T) {
+		assert.Equal(t, cast.Seconds(42.3), stats.Duration)
+		assert.Equal(t, 5, stats.Events)
+		assert.Equal(t, map[string]int{"o": 2, "i": 1, "r": 1, "m": 1}, stats.EventTypes)
+		assert.Equal(t, 8, stats.OutputBytes)
+		assert.Equal(t, 1, stats.Resizes)
+	})
+
+	t.Run("Builds the histogram of gaps", func(t *testing.T) {
+		counts := map[cast.Time]int{}
+		totals := map[cast.Time]cast.Time{}
+		for _, bucket := range stats.Gaps {
+			counts[bucket.From] = bucket.Count
+			totals[bucket.From] = bucket.Total
+		}
+
+		assert.Equal(t, map[cast.Time]int{
+			0:                      2,
+			100 * cast.Millisecond: 0,
+			250 * cast.Millisecond: 1,
+			500 * cast.Millisecond: 0,
+			cast.Second:            0,
+			2 * cast.Second:        1,
+			5 * cast.Second:        0,
+			10 * cast.Second:       0,
+			30 * cast.Second:       1,
+		}, counts)
+		assert.Equal(t, 50*cast.Millisecond, totals[0])
+		assert.Equal(t, 40*cast.Second, totals[30*cast.Second])
+		assert.Equal(t, cast.Time(0), stats.Gaps[len(stats.Gaps)-1].To)
+	})
+
+	t.Run("Lists the longest pauses", func(t *testing.T) {
+		assert.Equal(t, []cast.Pause{
+			{Time: cast.Seconds(2.3), Index: 4, Duration: cast.Seconds(40)},
+			{Time: cast.Seconds(0.3), Index: 2, Duration: cast.Seconds(2)},
+		}, stats.LongestPauses)
+	})
+}
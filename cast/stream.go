@@ -0,0 +1,658 @@
+package cast
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// EventSource is anything that yields the events of a cast one at a
+// time, returning `io.EOF` once there are no more events.
+type EventSource interface {
+	NextEvent() (*Event, error)
+}
+
+// EventSink is anything that takes the events of a cast one at a time.
+type EventSink interface {
+	WriteEvent(ev *Event) error
+}
+
+// Reader decodes a cast from an `io.Reader` one event at a time so that
+// casts can be processed without holding them entirely in memory.
+//
+// Besides v2 casts, v1 and v3 recordings are accepted as well. Given
+// that v1 recordings consist of a single JSON document, they're decoded
+// at once and presented as an equivalent v2 cast. The intervals of v3
+// events are converted into timestamps.
+type Reader struct {
+	decoder  *json.Decoder
+	header   Header
+	ev       [3]interface{}
+	lastTime Time
+
+	// pending holds the events of a cast that had to be decoded at
+	// once (v1).
+	pending []*Event
+	v1      bool
+}
+
+// NewReader creates a Reader, decoding the cast header right away.
+func NewReader(reader io.Reader) (*Reader, error) {
+	if reader == nil {
+		return nil, errors.New("a reader must be specified")
+	}
+
+	r := &Reader{
+		decoder: json.NewDecoder(reader),
+		header: Header{
+			Version: 2,
+		},
+	}
+
+	// times are kept as they're written so that they're parsed exactly.
+	r.decoder.UseNumber()
+
+	var raw json.RawMessage
+
+	err := r.decoder.Decode(&raw)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"couldn't decode header")
+	}
+
+	version, err := probeVersion(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"couldn't decode header")
+	}
+
+	if version == 1 {
+		r.header, r.pending, err = decodeV1(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		r.v1 = true
+		return r, nil
+	}
+
+	if version == 3 {
+		r.header, err = decodeV3Header(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return r, nil
+	}
+
+	err = json.Unmarshal(raw, &r.header)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"couldn't decode header")
+	}
+
+	return r, nil
+}
+
+// Header returns the header of the cast being read.
+func (r *Reader) Header() *Header {
+	return &r.header
+}
+
+// NextEvent decodes the next event of the event stream, returning
+// `io.EOF` when the stream has been fully consumed.
+func (r *Reader) NextEvent() (*Event, error) {
+	if r.v1 {
+		if len(r.pending) == 0 {
+			return nil, io.EOF
+		}
+
+		ev := r.pending[0]
+		r.pending = r.pending[1:]
+
+		return ev, nil
+	}
+
+	var (
+		ok     bool
+		number json.Number
+		time   Time
+		evType string
+		data   string
+	)
+
+	err := r.decoder.Decode(&r.ev)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+
+		return nil, errors.Wrapf(err,
+			"failed to parse ev line")
+	}
+
+	number, ok = r.ev[0].(json.Number)
+	if !ok {
+		return nil, errors.Errorf("first element of event is not a number")
+	}
+
+	time, err = ParseTime(number.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid event time")
+	}
+
+	evType, ok = r.ev[1].(string)
+	if !ok {
+		return nil, errors.Errorf("second element of event is not a string")
+	}
+
+	data, ok = r.ev[2].(string)
+	if !ok {
+		return nil, errors.Errorf("third element of event is not a string")
+	}
+
+	if r.header.Version == 3 {
+		if time < 0 {
+			return nil, errors.Errorf("event interval must not be negative")
+		}
+
+		time += r.lastTime
+		r.lastTime = time
+	}
+
+	ev := &Event{
+		Time: time,
+		Type: evType,
+		Data: data,
+	}
+	if err = ev.ValidateEvent(); err != nil {
+		return nil, errors.Wrapf(err,
+			"invalid event")
+	}
+
+	return ev, nil
+}
+
+// Writer encodes a cast into an `io.Writer` one event at a time, using
+// the format of the version in the header it's been created with.
+type Writer struct {
+	encoder  *json.Encoder
+	version  uint8
+	lastTime Time
+}
+
+// NewWriter creates a Writer, encoding the cast header right away.
+func NewWriter(writer io.Writer, header *Header) (*Writer, error) {
+	if writer == nil {
+		return nil, errors.New("a writer must be specified")
+	}
+
+	if header == nil {
+		return nil, errors.New("a header must be specified")
+	}
+
+	w := &Writer{
+		encoder: json.NewEncoder(writer),
+		version: header.Version,
+	}
+
+	w.encoder.SetIndent("", "")
+
+	// the header is written directly so that the values of unknown
+	// keys are kept byte-for-byte.
+	data, err := header.marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode header")
+	}
+
+	_, err = writer.Write(append(data, '\n'))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode header")
+	}
+
+	return w, nil
+}
+
+// WriteEvent encodes a single event.
+//
+// Exit status (`x`) events only exist in v3, so they're left out of
+// v2 casts.
+func (w *Writer) WriteEvent(ev *Event) error {
+	if w.version != 3 && ev.Type == "x" {
+		return nil
+	}
+
+	if w.version == 3 {
+		interval := &Event{
+			Time: ev.Time - w.lastTime,
+			Type: ev.Type,
+			Data: ev.Data,
+		}
+
+		w.lastTime = ev.Time
+		ev = interval
+	}
+
+	if err := ev.Encode(w.encoder); err != nil {
+		return errors.Wrap(err, "failed to encode event")
+	}
+
+	return nil
+}
+
+// CopyStream writes every event read from `src` into `dst`.
+func CopyStream(src EventSource, dst EventSink) error {
+	for {
+		ev, err := src.NextEvent()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = dst.WriteEvent(ev)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sliceSource is an EventSource backed by an in-memory event stream.
+type sliceSource struct {
+	events []*Event
+}
+
+func (s *sliceSource) NextEvent() (*Event, error) {
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+
+	ev := s.events[0]
+	s.events = s.events[1:]
+
+	return ev, nil
+}
+
+// cursorReader walks an EventSource producing a cursor for each event,
+// reading ahead just enough to know the surroundings of the event:
+// the rest of its group, the timestamp of the next group and, within
+// `lookahead` events from the end, how many events are left.
+//
+// Consumers may rewrite the events they're handed (e.g., to shift
+// them), so the reader keeps the original timestamp of the last one
+// rather than looking it up again.
+type cursorReader struct {
+	src       EventSource
+	lookahead int
+	queue     []*Event
+	eof       bool
+	idx       int
+	cur       eventCursor
+	lastTime  Time
+}
+
+func newCursorReader(src EventSource, lookahead int) *cursorReader {
+	return &cursorReader{
+		src:       src,
+		lookahead: lookahead,
+	}
+}
+
+// fill reads from the source until `n` events are queued or the source
+// gets exhausted.
+func (r *cursorReader) fill(n int) error {
+	for !r.eof && len(r.queue) < n {
+		ev, err := r.src.NextEvent()
+		if err == io.EOF {
+			r.eof = true
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		r.queue = append(r.queue, ev)
+	}
+
+	return nil
+}
+
+// next returns the cursor of the next event or `io.EOF` once the source
+// has been exhausted.
+//
+// The cursor is reused across calls.
+func (r *cursorReader) next() (*eventCursor, error) {
+	err := r.fill(r.lookahead + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.queue) == 0 {
+		return nil, io.EOF
+	}
+
+	var (
+		cur = &r.cur
+		ev  = r.queue[0]
+	)
+
+	cur.firstOfGroup = r.idx == 0 || r.lastTime != ev.Time
+	if cur.firstOfGroup {
+		cur.hasPrev = r.idx != 0
+		if cur.hasPrev {
+			cur.prevTime = r.lastTime
+		}
+
+		groupEnd := 1
+		for {
+			err = r.fill(groupEnd + 1)
+			if err != nil {
+				return nil, err
+			}
+
+			if groupEnd >= len(r.queue) || r.queue[groupEnd].Time != ev.Time {
+				break
+			}
+
+			groupEnd++
+		}
+
+		cur.hasNext = groupEnd < len(r.queue)
+		if cur.hasNext {
+			cur.nextTime = r.queue[groupEnd].Time
+		}
+	}
+
+	cur.lastOfGroup = len(r.queue) < 2 || r.queue[1].Time != ev.Time
+
+	cur.remaining = -1
+	if r.eof {
+		cur.remaining = len(r.queue) - 1
+	}
+
+	cur.idx = r.idx
+	cur.event = ev
+
+	r.lastTime = ev.Time
+	r.idx++
+	r.queue = r.queue[1:]
+
+	return cur, nil
+}
+
+// CutStream is the streaming counterpart of `CutRange`: events are read
+// from `src` and, except for the ones delimited by `r`, written to `dst`
+// as soon as possible.
+func CutStream(src EventSource, dst EventSink, r Range, policy SnapPolicy) error {
+	err := r.Validate()
+	if err != nil {
+		return err
+	}
+
+	const (
+		before = iota
+		inside
+		after
+	)
+
+	var (
+		reader   = newCursorReader(src, r.lookahead())
+		state    = before
+		fromTime Time
+		delta    Time
+		shifting bool
+	)
+
+	for {
+		cur, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ev := cur.event
+
+		switch state {
+		case before:
+			if !r.From.matchesStart(cur, policy) {
+				if r.To.matchesEnd(cur, policy) {
+					return errors.Errorf(
+						"initial frame (%s) comes after final frame (%s)",
+						r.From, r.To)
+				}
+
+				err = dst.WriteEvent(ev)
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			fromTime = ev.Time
+			state = inside
+			fallthrough
+		case inside:
+			if r.To.matchesEnd(cur, policy) {
+				state = after
+			}
+		case after:
+			if !shifting {
+				delta = ev.Time - fromTime
+				shifting = true
+			}
+
+			ev.Time -= delta
+
+			err = dst.WriteEvent(ev)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch state {
+	case before:
+		return errors.Errorf("couldn't find initial frame")
+	case inside:
+		return errors.Errorf("couldn't find final frame")
+	}
+
+	return nil
+}
+
+// SpeedStream is the streaming counterpart of `SpeedRange`.
+func SpeedStream(src EventSource, dst EventSink, factor float64, r Range, policy SnapPolicy) error {
+	if factor > 10 || factor < 0.1 {
+		return errors.Errorf("factor must be within 0.1 and 10 range")
+	}
+
+	err := r.Validate()
+	if err != nil {
+		return err
+	}
+
+	const (
+		before = iota
+		inside
+		after
+	)
+
+	var (
+		reader   = newCursorReader(src, r.lookahead())
+		state    = before
+		prevTime Time
+		newTime  Time
+		shift    Time
+	)
+
+	for {
+		cur, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ev := cur.event
+
+		switch state {
+		case before:
+			if r.From.matchesStart(cur, policy) {
+				if r.To.matchesEnd(cur, policy) {
+					return errors.Errorf("`from` must not be greater or equal than `to`")
+				}
+
+				state = inside
+			} else if r.To.matchesEnd(cur, policy) {
+				return errors.Errorf(
+					"initial frame (%s) comes after final frame (%s)",
+					r.From, r.To)
+			}
+
+			prevTime = ev.Time
+			newTime = ev.Time
+		case inside:
+			newTime += (ev.Time - prevTime).Scale(factor)
+			prevTime = ev.Time
+
+			if r.To.matchesEnd(cur, policy) {
+				shift = newTime - ev.Time
+				state = after
+			}
+
+			ev.Time = newTime
+		case after:
+			ev.Time += shift
+		}
+
+		err = dst.WriteEvent(ev)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch state {
+	case before:
+		return errors.Errorf("couldn't find initial frame")
+	case inside:
+		return errors.Errorf("couldn't find final frame")
+	}
+
+	return nil
+}
+
+// QuantizeStream is the streaming counterpart of `Quantize`.
+func QuantizeStream(src EventSource, dst EventSink, ranges []QuantizeRange) error {
+	return QuantizeScopedStream(src, dst, ranges, QuantizeScope{})
+}
+
+// QuantizeScopedStream is the streaming counterpart of `QuantizeScoped`.
+func QuantizeScopedStream(src EventSource, dst EventSink, ranges []QuantizeRange, scope QuantizeScope) error {
+	err := ValidateQuantizeRanges(ranges)
+	if err != nil {
+		return err
+	}
+
+	const (
+		before = iota
+		inside
+		after
+	)
+
+	var (
+		span     = Range{From: EventAt(0), To: EventAt(-1)}
+		reader   *cursorReader
+		state    = before
+		prevTime Time
+		newTime  Time
+		shift    Time
+		count    int
+	)
+
+	if scope.Span != nil {
+		span = *scope.Span
+
+		err = span.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	reader = newCursorReader(src, span.lookahead())
+
+	for {
+		cur, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		ev := cur.event
+		count++
+
+		switch state {
+		case before:
+			if span.From.matchesStart(cur, scope.Policy) {
+				state = inside
+				if span.To.matchesEnd(cur, scope.Policy) {
+					state = after
+				}
+			} else if span.To.matchesEnd(cur, scope.Policy) {
+				return errors.Errorf(
+					"initial frame (%s) comes after final frame (%s)",
+					span.From, span.To)
+			}
+
+			prevTime = ev.Time
+			newTime = ev.Time
+		case inside:
+			delta := ev.Time - prevTime
+			if scope.covers(ev) {
+				delta = quantizeDelta(delta, ranges)
+			}
+
+			newTime += delta
+			prevTime = ev.Time
+
+			if span.To.matchesEnd(cur, scope.Policy) {
+				shift = newTime - ev.Time
+				state = after
+			}
+
+			ev.Time = newTime
+		case after:
+			ev.Time += shift
+		}
+
+		err = dst.WriteEvent(ev)
+		if err != nil {
+			return err
+		}
+	}
+
+	if count == 0 {
+		return errors.Errorf("event stream must not be empty")
+	}
+
+	switch state {
+	case before:
+		return errors.Errorf("couldn't find initial frame")
+	case inside:
+		return errors.Errorf("couldn't find final frame")
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package cast_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+const streamCast = `{"version": 2, "width": 80, "height": 24}
+[1, "o", "a"]
+[1.5, "o", "b"]
+[1.5, "i", "c"]
+[3, "o", "d"]
+[7, "o", "e"]
+[7.25, "o", "f"]
+`
+
+// collector is an EventSink that keeps the events in memory.
+type collector struct {
+	events []*cast.Event
+}
+
+func (c *collector) WriteEvent(ev *cast.Event) error {
+	c.events = append(c.events, ev)
+	return nil
+}
+
+func newStreamReader(t *testing.T) *cast.Reader {
+	reader, err := cast.NewReader(strings.NewReader(streamCast))
+	assert.NoError(t, err)
+	return reader
+}
+
+func decodeStreamCast(t *testing.T) *cast.Cast {
+	c, err := cast.Decode(strings.NewReader(streamCast))
+	assert.NoError(t, err)
+	return c
+}
+
+func TestReader(t *testing.T) {
+	t.Run("With nil reader", func(t *testing.T) {
+		_, err := cast.NewReader(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("With malformed header", func(t *testing.T) {
+		_, err := cast.NewReader(strings.NewReader("malformed"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Reads events one at a time", func(t *testing.T) {
+		reader := newStreamReader(t)
+		assert.Equal(t, uint(80), reader.Header().Width)
+
+		ev, err := reader.NextEvent()
+		assert.NoError(t, err)
+		assert.Equal(t, &cast.Event{Time: cast.Seconds(1), Type: "o", Data: "a"}, ev)
+
+		count := 1
+		for {
+			_, err = reader.NextEvent()
+			if err == io.EOF {
+				break
+			}
+
+			assert.NoError(t, err)
+			count++
+		}
+
+		assert.Equal(t, 6, count)
+	})
+
+	t.Run("Fails on invalid events", func(t *testing.T) {
+		reader, err := cast.NewReader(strings.NewReader(`{"version": 2, "width": 80, "height": 24}
+[1, "z", "a"]`))
+		assert.NoError(t, err)
+
+		_, err = reader.NextEvent()
+		assert.Error(t, err)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("Parameter validation", func(t *testing.T) {
+		_, err := cast.NewWriter(nil, &cast.Header{})
+		assert.Error(t, err)
+
+		_, err = cast.NewWriter(&bytes.Buffer{}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Round trips through Reader", func(t *testing.T) {
+		var (
+			original = decodeStreamCast(t)
+			buf      = &bytes.Buffer{}
+		)
+
+		writer, err := cast.NewWriter(buf, &original.Header)
+		assert.NoError(t, err)
+
+		for _, ev := range original.EventStream {
+			assert.NoError(t, writer.WriteEvent(ev))
+		}
+
+		decoded, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, original, decoded)
+	})
+
+	t.Run("Leaves exit status events out of v2", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		writer, err := cast.NewWriter(buf, &cast.Header{Version: 2, Width: 80, Height: 24})
+		assert.NoError(t, err)
+
+		assert.NoError(t, writer.WriteEvent(&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "a"}))
+		assert.NoError(t, writer.WriteEvent(&cast.Event{Time: cast.Seconds(2), Type: "x", Data: "0"}))
+
+		assert.Equal(t, `{"version":2,"width":80,"height":24}
+[1.000000,"o","a"]
+`, buf.String())
+	})
+}
+
+func TestStreamTransformations(t *testing.T) {
+	tests := []struct {
+		name   string
+		batch  func(c *cast.Cast) error
+		stream func(src cast.EventSource, dst cast.EventSink) error
+	}{
+		{
+			name: "Cut",
+			batch: func(c *cast.Cast) error {
+				return cast.CutRange(c, cast.Range{From: cast.TimeAt(cast.Seconds(1.4)), To: cast.TimeAt(cast.Seconds(3))}, cast.SnapNearest)
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.CutStream(src, dst, cast.Range{From: cast.TimeAt(cast.Seconds(1.4)), To: cast.TimeAt(cast.Seconds(3))}, cast.SnapNearest)
+			},
+		},
+		{
+			name: "Cut up to the last event",
+			batch: func(c *cast.Cast) error {
+				return cast.CutRange(c, cast.Range{From: cast.EventAt(-2), To: cast.EventAt(-1)}, cast.SnapExact)
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.CutStream(src, dst, cast.Range{From: cast.EventAt(-2), To: cast.EventAt(-1)}, cast.SnapExact)
+			},
+		},
+		{
+			name: "Speed",
+			batch: func(c *cast.Cast) error {
+				return cast.SpeedRange(c, 0.5, cast.Range{From: cast.TimeAt(cast.Seconds(1.5)), To: cast.TimeAt(cast.Seconds(7))}, cast.SnapEnclose)
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.SpeedStream(src, dst, 0.5, cast.Range{From: cast.TimeAt(cast.Seconds(1.5)), To: cast.TimeAt(cast.Seconds(7))}, cast.SnapEnclose)
+			},
+		},
+		{
+			name: "Speed up to an end snapped forward to the nearest event",
+			batch: func(c *cast.Cast) error {
+				return cast.SpeedRange(c, 2, cast.Range{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(6))}, cast.SnapNearest)
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.SpeedStream(src, dst, 2, cast.Range{From: cast.TimeAt(cast.Seconds(1)), To: cast.TimeAt(cast.Seconds(6))}, cast.SnapNearest)
+			},
+		},
+		{
+			name: "Speed up to an enclosing end",
+			batch: func(c *cast.Cast) error {
+				return cast.SpeedRange(c, 2, cast.Range{From: cast.TimeAt(cast.Seconds(1.2)), To: cast.TimeAt(cast.Seconds(4))}, cast.SnapEnclose)
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.SpeedStream(src, dst, 2, cast.Range{From: cast.TimeAt(cast.Seconds(1.2)), To: cast.TimeAt(cast.Seconds(4))}, cast.SnapEnclose)
+			},
+		},
+		{
+			name: "Quantize",
+			batch: func(c *cast.Cast) error {
+				return cast.Quantize(c, []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.Seconds(10)}})
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.QuantizeStream(src, dst, []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.Seconds(10)}})
+			},
+		},
+		{
+			name: "Scoped quantize",
+			batch: func(c *cast.Cast) error {
+				return cast.QuantizeScoped(c, []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.MaxTime}}, cast.QuantizeScope{
+					Span:   &cast.Range{From: cast.TimeAt(cast.Seconds(1.5)), To: cast.EventAt(-2)},
+					Policy: cast.SnapExact,
+					Before: []string{"o"},
+				})
+			},
+			stream: func(src cast.EventSource, dst cast.EventSink) error {
+				return cast.QuantizeScopedStream(src, dst, []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.MaxTime}}, cast.QuantizeScope{
+					Span:   &cast.Range{From: cast.TimeAt(cast.Seconds(1.5)), To: cast.EventAt(-2)},
+					Policy: cast.SnapExact,
+					Before: []string{"o"},
+				})
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" matches the in-memory transformation", func(t *testing.T) {
+			expected := decodeStreamCast(t)
+			assert.NoError(t, test.batch(expected))
+
+			sink := &collector{}
+			assert.NoError(t, test.stream(newStreamReader(t), sink))
+			assert.Equal(t, expected.EventStream, sink.events)
+		})
+	}
+
+	t.Run("Cut fails if the initial frame can't be found", func(t *testing.T) {
+		err := cast.CutStream(newStreamReader(t), &collector{},
+			cast.Range{From: cast.TimeAt(cast.Seconds(2)), To: cast.TimeAt(cast.Seconds(3))}, cast.SnapExact)
+		assert.Error(t, err)
+	})
+
+	t.Run("Speed fails if the range covers a single event", func(t *testing.T) {
+		err := cast.SpeedStream(newStreamReader(t), &collector{}, 2,
+			cast.Range{From: cast.TimeAt(cast.Seconds(3)), To: cast.TimeAt(cast.Seconds(3))}, cast.SnapExact)
+		assert.Error(t, err)
+	})
+
+	t.Run("Quantize fails on an empty stream", func(t *testing.T) {
+		reader, err := cast.NewReader(strings.NewReader(`{"version": 2, "width": 80, "height": 24}`))
+		assert.NoError(t, err)
+
+		err = cast.QuantizeStream(reader, &collector{}, []cast.QuantizeRange{{From: cast.Seconds(1), To: cast.Seconds(2)}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,218 @@
+package cast
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// textScanner follows the escape sequences of terminal output, telling
+// the characters that get printed apart from the ones that are part of
+// control sequences. Its state carries over from one event to the next
+// so that sequences split across events are recognized.
+type textScanner struct {
+	state textState
+}
+
+type textState uint8
+
+const (
+	textGround textState = iota
+	textEscape
+	textCSI
+
+	// textString is the body of an OSC, DCS, SOS, PM or APC sequence,
+	// which ends with BEL or ST (ESC \).
+	textString
+	textStringEscape
+)
+
+// printable tells whether `r` gets printed, updating the state of the
+// scanner.
+func (s *textScanner) printable(r rune) bool {
+	switch s.state {
+	case textGround:
+		switch {
+		case r == 0x1b:
+			s.state = textEscape
+		case r == 0x9b:
+			s.state = textCSI
+		case r == 0x9d || r == 0x90:
+			s.state = textString
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+		default:
+			return true
+		}
+	case textEscape:
+		switch {
+		case r == '[':
+			s.state = textCSI
+		case r == ']' || r == 'P' || r == 'X' || r == '^' || r == '_':
+			s.state = textString
+		case r >= 0x20 && r <= 0x2f:
+			// intermediate bytes (e.g. `ESC ( B`).
+		default:
+			s.state = textGround
+		}
+	case textCSI:
+		if r >= 0x40 && r <= 0x7e {
+			s.state = textGround
+		}
+	case textString:
+		switch r {
+		case 0x07, 0x9c:
+			s.state = textGround
+		case 0x1b:
+			s.state = textStringEscape
+		}
+	case textStringEscape:
+		if r == '\\' {
+			s.state = textGround
+		} else {
+			s.state = textString
+		}
+	}
+
+	return false
+}
+
+// textStream is the text of the events of a given type, one after the
+// other and without escape sequences, so that text split across events
+// (or broken up by escape sequences) can be searched as a whole.
+type textStream struct {
+	text string
+
+	// events and offsets tell, for each byte of the text, the index
+	// of the event it comes from and its offset in the event's data.
+	events  []int
+	offsets []int
+}
+
+// newTextStream gathers the text of the events of type `evType`.
+func newTextStream(events []*Event, evType string) *textStream {
+	var (
+		s       = &textStream{}
+		buf     strings.Builder
+		scanner textScanner
+	)
+
+	for idx, ev := range events {
+		if ev.Type != evType {
+			continue
+		}
+
+		for offset, r := range ev.Data {
+			ground := scanner.state == textGround
+			if !scanner.printable(r) && !(ground && scanner.state == textGround) {
+				continue
+			}
+
+			size := utf8.RuneLen(r)
+			if r == utf8.RuneError {
+				_, size = utf8.DecodeRuneInString(ev.Data[offset:])
+			}
+
+			buf.WriteString(ev.Data[offset : offset+size])
+			for i := 0; i < size; i++ {
+				s.events = append(s.events, idx)
+				s.offsets = append(s.offsets, offset+i)
+			}
+		}
+	}
+
+	s.text = buf.String()
+
+	return s
+}
+
+// textEdit replaces `text[from:to]` of a text stream with `text`.
+type textEdit struct {
+	from, to int
+	text     string
+}
+
+// applyEdits rewrites the data of the events that the edited text comes
+// from. Edits must be sorted and must not overlap.
+//
+// The text replacing a span that comes from several events is split
+// among them in proportion to the characters each of them held, so that
+// events keep their timing (e.g. for text typed one key at a time).
+// Escape sequences within the span are left in place.
+func (s *textStream) applyEdits(events []*Event, edits []textEdit) {
+	var (
+		deleted  = map[int]map[int]bool{}
+		inserted = map[int]map[int]string{}
+	)
+
+	for _, edit := range edits {
+		if edit.from >= edit.to {
+			continue
+		}
+
+		// split the span into runs of bytes coming from the same
+		// event, counting their characters.
+		type run struct{ from, to, chars int }
+
+		var (
+			runs  []run
+			total int
+		)
+
+		for pos := edit.from; pos < edit.to; pos++ {
+			if len(runs) == 0 || s.events[pos] != s.events[runs[len(runs)-1].from] {
+				runs = append(runs, run{from: pos})
+			}
+
+			last := &runs[len(runs)-1]
+			last.to = pos + 1
+			if utf8.RuneStart(s.text[pos]) {
+				last.chars++
+				total++
+			}
+		}
+
+		var (
+			text  = []rune(edit.text)
+			start int
+			chars int
+		)
+
+		for idx, r := range runs {
+			chars += r.chars
+
+			end := len(text)
+			if idx < len(runs)-1 && total > 0 {
+				end = len(text) * chars / total
+			}
+
+			ev := s.events[r.from]
+			if deleted[ev] == nil {
+				deleted[ev] = map[int]bool{}
+				inserted[ev] = map[int]string{}
+			}
+
+			for pos := r.from; pos < r.to; pos++ {
+				deleted[ev][s.offsets[pos]] = true
+			}
+
+			inserted[ev][s.offsets[r.from]] += string(text[start:end])
+			start = end
+		}
+	}
+
+	for idx, offsets := range deleted {
+		var (
+			data = events[idx].Data
+			buf  strings.Builder
+		)
+
+		for offset := 0; offset < len(data); offset++ {
+			buf.WriteString(inserted[idx][offset])
+
+			if !offsets[offset] {
+				buf.WriteByte(data[offset])
+			}
+		}
+
+		events[idx].Data = buf.String()
+	}
+}
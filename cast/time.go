@@ -0,0 +1,168 @@
+package cast
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Time is a point of a recording (or a span of it), counted in
+// microseconds - the precision that asciinema records events with.
+//
+// Keeping times as integers makes every transformation exact: times
+// read from a cast can be given back to a command and match the events
+// they came from, no matter how many edits happened in between.
+type Time int64
+
+const (
+	Microsecond Time = 1
+	Millisecond      = 1000 * Microsecond
+	Second           = 1000 * Millisecond
+	Minute           = 60 * Second
+	Hour             = 60 * Minute
+)
+
+// MaxTime is the latest time that can be represented, standing for an
+// unbounded time.
+const MaxTime Time = math.MaxInt64
+
+// timeDecimals is the number of decimals that times are printed with.
+const timeDecimals = 6
+
+// Seconds converts a number of seconds into the closest Time.
+func Seconds(seconds float64) Time {
+	return Time(math.Round(seconds * float64(Second)))
+}
+
+// Seconds returns the time as a number of seconds.
+func (t Time) Seconds() float64 {
+	return float64(t) / float64(Second)
+}
+
+// Duration converts the time into a `time.Duration`.
+func (t Time) Duration() time.Duration {
+	return time.Duration(t) * time.Microsecond
+}
+
+// Scale multiplies the time by `factor`, rounding the result to the
+// closest microsecond.
+func (t Time) Scale(factor float64) Time {
+	return Time(math.Round(float64(t) * factor))
+}
+
+// String returns the time in seconds with six decimals (e.g.,
+// `1.500000`), just like asciinema writes timestamps.
+func (t Time) String() string {
+	var (
+		sign  string
+		value = uint64(t)
+	)
+
+	if t < 0 {
+		sign = "-"
+		value = uint64(-t)
+	}
+
+	frac := strconv.FormatUint(value%uint64(Second), 10)
+
+	return sign + strconv.FormatUint(value/uint64(Second), 10) + "." +
+		strings.Repeat("0", timeDecimals-len(frac)) + frac
+}
+
+// MarshalJSON encodes the time as a number of seconds with six decimals.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalJSON decodes a number of seconds (see `ParseTime`).
+func (t *Time) UnmarshalJSON(data []byte) error {
+	value, err := ParseTime(string(data))
+	if err != nil {
+		return err
+	}
+
+	*t = value
+	return nil
+}
+
+// ParseTime parses a decimal number of seconds (`12`, `1.5`, `2e-3`, ...)
+// without going through floating point, so that the six decimals that
+// asciinema writes are kept exactly. Further decimals are rounded.
+func ParseTime(input string) (Time, error) {
+	var (
+		s        = input
+		negative bool
+		exponent int
+		err      error
+	)
+
+	malformed := errors.Errorf("malformed time '%s'", input)
+
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	if idx := strings.IndexAny(s, "eE"); idx != -1 {
+		exponent, err = strconv.Atoi(strings.TrimPrefix(s[idx+1:], "+"))
+		if err != nil {
+			return 0, malformed
+		}
+
+		s = s[:idx]
+	}
+
+	integer, fraction := s, ""
+	if idx := strings.IndexByte(s, '.'); idx != -1 {
+		integer, fraction = s[:idx], s[idx+1:]
+	}
+
+	digits := strings.TrimLeft(integer+fraction, "0")
+	if integer == "" && fraction == "" {
+		return 0, malformed
+	}
+
+	for _, r := range integer + fraction {
+		if r < '0' || r > '9' {
+			return 0, malformed
+		}
+	}
+
+	if digits == "" {
+		return 0, nil
+	}
+
+	// `digits` holds the significant digits of the number, the last of
+	// the microseconds being `exponent + timeDecimals` digits away
+	// from the decimal point.
+	end := len(digits) - len(fraction) + exponent + timeDecimals
+	if end-len(digits) > 18 {
+		return 0, errors.Errorf("time '%s' is out of range", input)
+	}
+
+	var value Time
+
+	for idx := 0; idx < end; idx++ {
+		if value > math.MaxInt64/10-1 {
+			return 0, errors.Errorf("time '%s' is out of range", input)
+		}
+
+		value *= 10
+		if idx < len(digits) {
+			value += Time(digits[idx] - '0')
+		}
+	}
+
+	if end >= 0 && end < len(digits) && digits[end] >= '5' {
+		value++
+	}
+
+	if negative {
+		value = -value
+	}
+
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package cast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected cast.Time
+		fails    bool
+	}{
+		{"0", 0, false},
+		{"12", 12 * cast.Second, false},
+		{"1.5", 1500 * cast.Millisecond, false},
+		{"0.000001", cast.Microsecond, false},
+		{"1.123456", cast.Seconds(1.123456), false},
+		{".25", 250 * cast.Millisecond, false},
+		{"-0.5", -500 * cast.Millisecond, false},
+		{"2e-3", 2 * cast.Millisecond, false},
+		{"1.5E2", 150 * cast.Second, false},
+		{"0.0000005", cast.Microsecond, false},
+		{"0.0000004", 0, false},
+		{"", 0, true},
+		{".", 0, true},
+		{"1a", 0, true},
+		{"1e", 0, true},
+		{"1e30", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			res, err := cast.ParseTime(test.input)
+			if test.fails {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	t.Run("Prints six decimals", func(t *testing.T) {
+		assert.Equal(t, "0.000000", cast.Time(0).String())
+		assert.Equal(t, "1.500000", cast.Seconds(1.5).String())
+		assert.Equal(t, "62.000042", (62*cast.Second + 42).String())
+		assert.Equal(t, "-0.250000", cast.Seconds(-0.25).String())
+	})
+
+	t.Run("Scales to the closest microsecond", func(t *testing.T) {
+		assert.Equal(t, cast.Seconds(0.3), cast.Seconds(1).Scale(0.3))
+		assert.Equal(t, cast.Time(2), cast.Time(5).Scale(1.0/3))
+	})
+
+	t.Run("Round trips through JSON", func(t *testing.T) {
+		for _, input := range []string{"0.100000", "1.350224", "8.999999", "123456.000001"} {
+			var res cast.Time
+
+			assert.NoError(t, json.Unmarshal([]byte(input), &res))
+
+			data, err := json.Marshal(res)
+			assert.NoError(t, err)
+			assert.Equal(t, input, string(data))
+		}
+	})
+
+	t.Run("Doesn't decode strings", func(t *testing.T) {
+		var res cast.Time
+
+		assert.Error(t, json.Unmarshal([]byte(`"1.5"`), &res))
+	})
+}
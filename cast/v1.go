@@ -0,0 +1,122 @@
+package cast
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// v1Cast represents an asciicast v1 recording: a single JSON document
+// whose `stdout` field holds `[delay, data]` pairs, `delay` being the
+// number of seconds since the previous frame.
+//
+// [1]: https://github.com/asciinema/asciinema/blob/49a892d9e6f57ab3a774c0835fa563c77cf6a7a7/doc/asciicast-v1.md
+type v1Cast struct {
+	Version  uint8             `json:"version"`
+	Width    uint              `json:"width"`
+	Height   uint              `json:"height"`
+	Duration float64           `json:"duration"`
+	Command  string            `json:"command"`
+	Title    string            `json:"title"`
+	Env      map[string]string `json:"env"`
+	Stdout   [][]interface{}   `json:"stdout"`
+}
+
+// versionProbe is used to find out the version of a cast before
+// decoding its header.
+type versionProbe struct {
+	Version uint8 `json:"version"`
+}
+
+// probeVersion returns the version declared by an encoded header (or
+// v1 document), zero if it doesn't declare any.
+func probeVersion(raw json.RawMessage) (uint8, error) {
+	var probe versionProbe
+
+	err := json.Unmarshal(raw, &probe)
+	if err != nil {
+		return 0, err
+	}
+
+	return probe.Version, nil
+}
+
+// v1Keys are the keys modeled by v1Cast.
+var v1Keys = jsonKeys(v1Cast{})
+
+// decodeV1 converts an asciicast v1 document into a v2 header and its
+// corresponding event stream.
+//
+// Keys that aren't part of the v1 format are kept in `Header.Extra`.
+func decodeV1(raw json.RawMessage) (header Header, events []*Event, err error) {
+	var v1 v1Cast
+
+	// delays are kept as they're written so that they're parsed exactly.
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	err = decoder.Decode(&v1)
+	if err != nil {
+		err = errors.Wrapf(err, "couldn't decode v1 cast")
+		return
+	}
+
+	header = Header{
+		Version: 2,
+		Width:   v1.Width,
+		Height:  v1.Height,
+		Command: v1.Command,
+		Title:   v1.Title,
+		Env:     v1.Env,
+	}
+
+	header.Extra, err = extraFields(raw, v1Keys)
+	if err != nil {
+		return
+	}
+
+	var elapsed Time
+
+	events = make([]*Event, 0, len(v1.Stdout))
+	for idx, frame := range v1.Stdout {
+		if len(frame) != 2 {
+			err = errors.Errorf("stdout frame %d must have 2 elements", idx)
+			return
+		}
+
+		number, ok := frame[0].(json.Number)
+		if !ok {
+			err = errors.Errorf("first element of stdout frame %d is not a number", idx)
+			return
+		}
+
+		var delay Time
+
+		delay, err = ParseTime(number.String())
+		if err != nil {
+			err = errors.Wrapf(err, "invalid delay in stdout frame %d", idx)
+			return
+		}
+
+		if delay < 0 {
+			err = errors.Errorf("stdout frame %d has a negative delay", idx)
+			return
+		}
+
+		data, ok := frame[1].(string)
+		if !ok {
+			err = errors.Errorf("second element of stdout frame %d is not a string", idx)
+			return
+		}
+
+		elapsed += delay
+		events = append(events, &Event{
+			Time: elapsed,
+			Type: "o",
+			Data: data,
+		})
+	}
+
+	return
+}
@@ -0,0 +1,71 @@
+package cast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+const v1Cast = `{
+  "version": 1,
+  "width": 80,
+  "height": 24,
+  "duration": 1.515658,
+  "command": "/bin/zsh",
+  "title": "demo",
+  "env": {"TERM": "xterm-256color", "SHELL": "/bin/zsh"},
+  "stdout": [
+    [0.248848, "\u001b[1;31mHello \u001b[32mWorld!\u001b[0m\n"],
+    [1.001376, "I am \rThis is on the next line."],
+    [0.265434, "bye"]
+  ]
+}`
+
+func TestDecode_V1(t *testing.T) {
+	t.Run("Converts frames into v2 events", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(v1Cast))
+		assert.NoError(t, err)
+		assert.NoError(t, c.Validate())
+
+		assert.Equal(t, uint8(2), c.Header.Version)
+		assert.Equal(t, uint(80), c.Header.Width)
+		assert.Equal(t, uint(24), c.Header.Height)
+		assert.Equal(t, "/bin/zsh", c.Header.Command)
+		assert.Equal(t, "demo", c.Header.Title)
+		assert.Equal(t, map[string]string{
+			"SHELL": "/bin/zsh",
+			"TERM":  "xterm-256color",
+		}, c.Header.Env)
+		assert.Nil(t, c.Header.Extra)
+
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0.248848), Type: "o", Data: "\u001b[1;31mHello \u001b[32mWorld!\u001b[0m\n"},
+			{Time: cast.Seconds(1.250224), Type: "o", Data: "I am \rThis is on the next line."},
+			{Time: cast.Seconds(1.515658), Type: "o", Data: "bye"},
+		}, c.EventStream)
+	})
+
+	t.Run("Fails on malformed frames", func(t *testing.T) {
+		tests := []string{
+			`{"version": 1, "width": 80, "height": 24, "stdout": [[0.1]]}`,
+			`{"version": 1, "width": 80, "height": 24, "stdout": [["a", "b"]]}`,
+			`{"version": 1, "width": 80, "height": 24, "stdout": [[0.1, 2]]}`,
+			`{"version": 1, "width": 80, "height": 24, "stdout": [[-0.1, "a"]]}`,
+		}
+
+		for _, test := range tests {
+			_, err := cast.Decode(strings.NewReader(test))
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestDecode_V1_UnknownKeys(t *testing.T) {
+	c, err := cast.Decode(strings.NewReader(
+		`{"version": 1, "width": 80, "height": 24, "custom": [1, 2], "stdout": []}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "[1, 2]", string(c.Header.Extra["custom"]))
+}
@@ -0,0 +1,143 @@
+package cast
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// v3Term represents the `term` object of an asciicast v3 header.
+type v3Term struct {
+	Cols    uint   `json:"cols"`
+	Rows    uint   `json:"rows"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version,omitempty"`
+	Theme   *Theme `json:"theme,omitempty"`
+
+	// Extra holds the `term` keys that aren't modeled by this struct.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// v3TermFields has the same fields as v3Term but none of its methods.
+type v3TermFields v3Term
+
+// v3TermKeys are the `term` keys modeled by v3Term.
+var v3TermKeys = jsonKeys(v3TermFields{})
+
+// UnmarshalJSON decodes an encoded `term` object, keeping any keys that
+// aren't modeled in `Extra`.
+func (term *v3Term) UnmarshalJSON(data []byte) error {
+	fields := v3TermFields(*term)
+
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	fields.Extra, err = extraFields(data, v3TermKeys)
+	if err != nil {
+		return err
+	}
+
+	*term = v3Term(fields)
+	return nil
+}
+
+// MarshalJSON encodes the `term` object, appending the keys in `Extra`
+// with their values untouched.
+func (term *v3Term) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra((*v3TermFields)(term), term.Extra)
+}
+
+// v3Header represents the header of an asciicast v3 recording.
+//
+// In v3, the terminal information lives under `term` (with the terminal
+// type that v2 keeps in `env.TERM`) and event times are intervals
+// relative to the previous event.
+//
+// [1]: https://docs.asciinema.org/manual/asciicast/v3/
+type v3Header struct {
+	Version       uint8             `json:"version"`
+	Term          v3Term            `json:"term"`
+	Timestamp     uint              `json:"timestamp,omitempty"`
+	IdleTimeLimit float64           `json:"idle_time_limit,omitempty"`
+	Command       string            `json:"command,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
+}
+
+// toV3 converts the header into its v3 representation.
+func (header *Header) toV3() *v3Header {
+	res := &v3Header{
+		Version: 3,
+		Term: v3Term{
+			Cols:    header.Width,
+			Rows:    header.Height,
+			Type:    header.Env["TERM"],
+			Version: header.TermVersion,
+			Theme:   header.Theme,
+			Extra:   header.TermExtra,
+		},
+		Timestamp:     header.Timestamp,
+		IdleTimeLimit: header.IdleTimeLimit,
+		Command:       header.Command,
+		Title:         header.Title,
+		Tags:          header.Tags,
+	}
+
+	for key, value := range header.Env {
+		if key == "TERM" {
+			continue
+		}
+
+		if res.Env == nil {
+			res.Env = make(map[string]string, len(header.Env))
+		}
+
+		res.Env[key] = value
+	}
+
+	return res
+}
+
+// v3HeaderKeys are the header keys modeled by v3Header.
+var v3HeaderKeys = jsonKeys(v3Header{})
+
+// decodeV3Header decodes an encoded asciicast v3 header into the
+// in-memory header model.
+func decodeV3Header(raw json.RawMessage) (header Header, err error) {
+	var v3 v3Header
+
+	err = json.Unmarshal(raw, &v3)
+	if err != nil {
+		err = errors.Wrapf(err, "couldn't decode v3 header")
+		return
+	}
+
+	header = Header{
+		Version:       3,
+		Width:         v3.Term.Cols,
+		Height:        v3.Term.Rows,
+		Timestamp:     v3.Timestamp,
+		Command:       v3.Command,
+		Title:         v3.Title,
+		IdleTimeLimit: v3.IdleTimeLimit,
+		Theme:         v3.Term.Theme,
+		Env:           v3.Env,
+		TermVersion:   v3.Term.Version,
+		Tags:          v3.Tags,
+		TermExtra:     v3.Term.Extra,
+	}
+
+	if v3.Term.Type != "" {
+		if header.Env == nil {
+			header.Env = make(map[string]string)
+		}
+
+		header.Env["TERM"] = v3.Term.Type
+	}
+
+	header.Extra, err = extraFields(raw, v3HeaderKeys)
+	return
+}
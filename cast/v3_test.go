@@ -0,0 +1,149 @@
+package cast_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+const v3Cast = `{"version": 3, "term": {"cols": 80, "rows": 24, "type": "xterm-256color", "version": "VTE(7000)"}, "timestamp": 1504467315, "idle_time_limit": 2, "command": "/bin/bash", "env": {"SHELL": "/bin/bash"}, "tags": ["demo"]}
+[0.248848, "o", "hi"]
+[1.001376, "i", "l"]
+[0.1, "r", "100x40"]
+[0, "m", "chapter"]
+[0.5, "x", "0"]
+`
+
+func TestDecode_V3(t *testing.T) {
+	t.Run("Converts intervals into timestamps", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(v3Cast))
+		assert.NoError(t, err)
+		assert.NoError(t, c.Validate())
+
+		assert.Equal(t, uint8(3), c.Header.Version)
+		assert.Equal(t, uint(80), c.Header.Width)
+		assert.Equal(t, uint(24), c.Header.Height)
+		assert.Equal(t, map[string]string{
+			"SHELL": "/bin/bash",
+			"TERM":  "xterm-256color",
+		}, c.Header.Env)
+		assert.Equal(t, "VTE(7000)", c.Header.TermVersion)
+		assert.Equal(t, []string{"demo"}, c.Header.Tags)
+
+		assert.Equal(t, []*cast.Event{
+			{Time: cast.Seconds(0.248848), Type: "o", Data: "hi"},
+			{Time: cast.Seconds(1.250224), Type: "i", Data: "l"},
+			{Time: cast.Seconds(1.350224), Type: "r", Data: "100x40"},
+			{Time: cast.Seconds(1.350224), Type: "m", Data: "chapter"},
+			{Time: cast.Seconds(1.850224), Type: "x", Data: "0"},
+		}, c.EventStream)
+	})
+
+	t.Run("Fails on negative intervals", func(t *testing.T) {
+		_, err := cast.Decode(strings.NewReader(`{"version": 3, "term": {"cols": 80, "rows": 24}}
+[-1, "o", "hi"]`))
+		assert.Error(t, err)
+	})
+}
+
+func TestEncode_V3(t *testing.T) {
+	t.Run("Writes intervals and the term object", func(t *testing.T) {
+		c := &cast.Cast{
+			Header: cast.Header{Version: 3, Width: 80, Height: 24},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "o", Data: "a"},
+				{Time: cast.Seconds(1.5), Type: "o", Data: "b"},
+				{Time: cast.Seconds(4), Type: "x", Data: "1"},
+			},
+		}
+		c.Header.Env = map[string]string{
+			"TERM":  "xterm",
+			"SHELL": "/bin/sh",
+		}
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Encode(buf))
+		assert.Equal(t, `{"version":3,"term":{"cols":80,"rows":24,"type":"xterm"},"env":{"SHELL":"/bin/sh"}}
+[1.000000,"o","a"]
+[0.500000,"o","b"]
+[2.500000,"x","1"]
+`, buf.String())
+
+		assert.Equal(t, cast.Seconds(1.5), c.EventStream[1].Time)
+	})
+
+	t.Run("Keeps unknown keys of the term object", func(t *testing.T) {
+		c, err := cast.Decode(strings.NewReader(`{"version": 3, "term": {"cols": 80, "rows": 24, "foo": "bar"}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `"bar"`, string(c.Header.TermExtra["foo"]))
+		assert.Empty(t, c.Header.Extra)
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, c.Encode(buf))
+		assert.Equal(t, "{\"version\":3,\"term\":{\"cols\":80,\"rows\":24,\"foo\":\"bar\"}}\n", buf.String())
+
+		decoded, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Header, decoded.Header)
+	})
+
+	t.Run("Keeps v3 header fields through v2", func(t *testing.T) {
+		original, err := cast.Decode(strings.NewReader(`{"version": 3, ` +
+			`"term": {"cols": 80, "rows": 24, "type": "xterm", "version": "VTE(7000)", "foo": "bar"}, ` +
+			`"tags": ["demo", "go"], "author": "me"}`))
+		assert.NoError(t, err)
+
+		v2 := *original
+		v2.Header.Version = 2
+
+		buf := &bytes.Buffer{}
+		assert.NoError(t, v2.Encode(buf))
+		assert.Equal(t, `{"version":2,"width":80,"height":24,"env":{"TERM":"xterm"},`+
+			`"author":"me","tags":["demo","go"],"term":{"version":"VTE(7000)","foo":"bar"}}`+"\n", buf.String())
+
+		decoded, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, v2.Header, decoded.Header)
+
+		decoded.Header.Version = 3
+		buf.Reset()
+		assert.NoError(t, decoded.Encode(buf))
+
+		roundTrip, err := cast.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, original.Header, roundTrip.Header)
+	})
+
+	t.Run("Converts between v2 and v3 without losses", func(t *testing.T) {
+		original, err := cast.Decode(strings.NewReader(v3Cast))
+		assert.NoError(t, err)
+
+		original.Header.Version = 2
+		v2 := &bytes.Buffer{}
+		assert.NoError(t, original.Encode(v2))
+
+		// v2 has no exit status events.
+		assert.Equal(t, "x", original.EventStream[len(original.EventStream)-1].Type)
+		original.EventStream = original.EventStream[:len(original.EventStream)-1]
+
+		decoded, err := cast.Decode(v2)
+		assert.NoError(t, err)
+		assert.Equal(t, original.EventStream, decoded.EventStream)
+		assert.Equal(t, original.Header.Env, decoded.Header.Env)
+
+		decoded.Header.Version = 3
+		v3 := &bytes.Buffer{}
+		assert.NoError(t, decoded.Encode(v3))
+
+		roundTrip, err := cast.Decode(v3)
+		assert.NoError(t, err)
+		assert.Equal(t, original.EventStream, roundTrip.EventStream)
+		assert.Equal(t, original.Header.Env, roundTrip.Header.Env)
+		assert.Equal(t, original.Header.Width, roundTrip.Header.Width)
+		assert.Equal(t, original.Header.IdleTimeLimit, roundTrip.Header.IdleTimeLimit)
+	})
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Anonymize = cli.Command{
+	Name: "anonymize",
+	Usage: `Removes the user, host and home directory from a cast.
+
+   The user name, host name and home directory the cast was recorded
+   with are detected from the working directories reported by the
+   shell (OSC 7 'file://host/path' sequences) and from the environment
+   in the header; more of them can be given with '--user', '--host' and
+   '--home'.
+
+   They're replaced with placeholders in the output, input and marker
+   events (text split across events included, see 'replace') as well
+   as in the payloads of OSC sequences: window titles (OSC 0, 1 and 2)
+   and working directories (OSC 7, whose host is always replaced).
+
+   The header loses its timestamp, and its environment is reduced to
+   'TERM' and 'SHELL'. The identity is replaced in its title, command
+   and tags as well as in the values of the keys that asciicast doesn't
+   define.
+
+   What got replaced is written to stderr.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default).
+
+EXAMPLES:
+   Anonymize a cast before sharing it:
+
+     asciinema-edit anonymize --out shared.cast 1234.cast
+
+   Replace a second user name too, and set a fixed window title:
+
+     asciinema-edit anonymize \
+       --user deploy \
+       --title demo \
+       --out shared.cast \
+       1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    anonymizeAction,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "user",
+			Usage: "user name to replace besides the detected ones (can be repeated)",
+		},
+		cli.StringSliceFlag{
+			Name:  "host",
+			Usage: "host name to replace besides the detected ones (can be repeated)",
+		},
+		cli.StringSliceFlag{
+			Name:  "home",
+			Usage: "home directory to replace besides the detected ones (can be repeated)",
+		},
+		cli.StringFlag{
+			Name:  "user-placeholder",
+			Usage: "text to replace user names with",
+			Value: cast.DefaultPlaceholders().User,
+		},
+		cli.StringFlag{
+			Name:  "host-placeholder",
+			Usage: "text to replace host names with",
+			Value: cast.DefaultPlaceholders().Host,
+		},
+		cli.StringFlag{
+			Name:  "home-placeholder",
+			Usage: "path to replace home directories with",
+			Value: cast.DefaultPlaceholders().Home,
+		},
+		cli.StringFlag{
+			Name:  "title",
+			Usage: "window title to replace every title with (defaults to anonymizing them)",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type anonymizeTransformation struct {
+	identity     cast.Identity
+	placeholders cast.Placeholders
+	count        int
+}
+
+func (t *anonymizeTransformation) Transform(c *cast.Cast) (err error) {
+	detected, err := cast.DetectIdentity(c)
+	if err != nil {
+		return
+	}
+
+	t.identity = t.identity.Merge(detected)
+
+	t.count, err = cast.Anonymize(c, t.identity, t.placeholders)
+	return
+}
+
+// writeAnonymization reports what got replaced.
+func writeAnonymization(w io.Writer, t *anonymizeTransformation) {
+	if len(t.identity.Users)+len(t.identity.Hosts)+len(t.identity.Homes) == 0 {
+		fmt.Fprintln(w, "no user, host or home directory found")
+	}
+
+	report := func(kind string, names []string, placeholder string) {
+		if len(names) > 0 {
+			fmt.Fprintf(w, "%s '%s' replaced with '%s'\n", kind, strings.Join(names, "', '"), placeholder)
+		}
+	}
+
+	report("user", t.identity.Users, t.placeholders.User)
+	report("host", t.identity.Hosts, t.placeholders.Host)
+	report("home", t.identity.Homes, t.placeholders.Home)
+
+	fmt.Fprintf(w, "%d replacement(s)\n", t.count)
+}
+
+func anonymizeAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().First()
+		output         = c.String("out")
+		transformation = &anonymizeTransformation{
+			identity: cast.Identity{
+				Users: c.StringSlice("user"),
+				Hosts: c.StringSlice("host"),
+				Homes: c.StringSlice("home"),
+			},
+			placeholders: cast.Placeholders{
+				User:  c.String("user-placeholder"),
+				Host:  c.String("host-placeholder"),
+				Home:  c.String("home-placeholder"),
+				Title: c.String("title"),
+			},
+		}
+	)
+
+	if !strings.HasPrefix(transformation.placeholders.Home, "/") {
+		err = cli.NewExitError(errors.Errorf("--home-placeholder must be an absolute path"), 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	writeAnonymization(os.Stderr, transformation)
+
+	return
+}
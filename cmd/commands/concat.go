@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Concat = cli.Command{
+	Name: "concat",
+	Usage: `Joins several casts into one.
+
+   The casts are played one after the other, each one starting '--gap'
+   seconds after the last event of the previous one. The resulting cast
+   takes the header (size, theme, environment...) of the first one.
+
+   Whenever a cast was recorded with a terminal size other than the one
+   the previous cast ended with, a resize ('r') event is inserted so
+   that it still renders at its own size.
+
+   With '--markers', a marker ('m') event labeled with the name of the
+   file (without its extension) is added at the beginning of every cast
+   but the first, which players show as chapters.
+
+   The resulting cast is either written to a file specified in the
+   '--out' flag or to stdout (default).
+
+EXAMPLES:
+   Join the parts of a demo with a second between them:
+
+     asciinema-edit concat \
+       --gap 1 \
+       --markers \
+       --out demo.cast \
+       intro.cast setup.cast usage.cast`,
+	ArgsUsage: "filename...",
+	Action:    concatAction,
+	Flags: []cli.Flag{
+		cli.Float64Flag{
+			Name:  "gap",
+			Usage: "seconds between the end of a cast and the beginning of the next",
+		},
+		cli.BoolFlag{
+			Name:  "markers",
+			Usage: "add a marker at the beginning of every cast but the first",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the joined cast to",
+		},
+		outputVersionFlag,
+	},
+}
+
+func concatAction(c *cli.Context) (err error) {
+	var (
+		inputs = c.Args()
+		output = c.String("out")
+		casts  = make([]*cast.Cast, 0, len(inputs))
+		opts   = cast.ConcatOptions{
+			Gap:     cast.Seconds(c.Float64("gap")),
+			Markers: c.Bool("markers"),
+		}
+	)
+
+	if len(inputs) == 0 {
+		err = cli.NewExitError(errors.Errorf("at least one file name must be specified"), 1)
+		return
+	}
+
+	version, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	for _, input := range inputs {
+		var part *cast.Cast
+
+		part, err = readCast(input)
+		if err != nil {
+			err = cli.NewExitError(errors.Wrapf(err, "failed to read %s", input), 1)
+			return
+		}
+
+		casts = append(casts, part)
+		opts.Labels = append(opts.Labels,
+			strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)))
+	}
+
+	res, err := cast.Concat(casts, opts)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if version != 0 {
+		res.Header.Version = version
+	}
+
+	out, err := createOutput(output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer out.Close()
+
+	err = res.Encode(out)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Convert = cli.Command{
+	Name: "convert",
+	Usage: `Converts a cast to the asciicast v2 (or v3) format.
+
+   Casts recorded with older asciinema versions (asciicast v1, a single
+   JSON document with a 'stdout' array of '[delay, data]' pairs) are
+   converted to the newline-delimited v2 format. Every other command
+   accepts v1 casts as input as well.
+
+   Casts can also be converted between v2 and v3 (where event times
+   are intervals relative to the previous event) with
+   '--output-version'. Exit status ('x') events only exist in v3, so
+   they're left out of v2 casts.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   Once the conversion has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default).
+
+EXAMPLES:
+   Convert an old v1 recording to v2:
+
+     asciinema-edit convert --out ./123.v2.cast ./123.json
+
+   Convert a v2 cast to v3:
+
+     asciinema-edit convert --output-version 3 ./123.cast`,
+	ArgsUsage: "[filename]",
+	Action:    convertAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the converted contents to",
+		},
+		cli.IntFlag{
+			Name:  "output-version",
+			Usage: "asciicast version of the output (2 or 3)",
+			Value: 2,
+		},
+	},
+}
+
+type convertTransformation struct{}
+
+func (t *convertTransformation) Transform(c *cast.Cast) (err error) {
+	return
+}
+
+func (t *convertTransformation) TransformStream(src cast.EventSource, dst cast.EventSink) (err error) {
+	err = cast.CopyStream(src, dst)
+	return
+}
+
+func convertAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().First()
+		output         = c.String("out")
+		transformation = &convertTransformation{}
+	)
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	return
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"github.com/wormbks/asciinema-edit/cast"
 	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"github.com/wormbks/asciinema-edit/vt"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -17,6 +18,18 @@ var Cut = cli.Command{
    either written to a file specified in the '--out' flag or to stdout
    (default).
 
+   Several ranges can be removed at once with '--range' (instead of
+   '--start' and '--end'), all of them referring to the timestamps of
+   the original cast: there's no need to work out where a range ends up
+   once the ones before it are removed. Ranges must not overlap.
+
+   With '--preserve', what the removed frames changed is kept: the last
+   resize ('r') event and the markers ('m') among them are moved to
+   where the cut happens, along with an output event redrawing the
+   screen as it was at the final frame, so that the frames that follow
+   look right (as in full-screen programs like vim or htop).
+` + positionUsage + `
+
 EXAMPLES:
    Remove frames from 12.2s to 16.3s from the cast passed in the commands
    stdin.
@@ -30,32 +43,90 @@ EXAMPLES:
 
      asciinema-edit cut \
        --start=12.2 --end=12.2 \
+       --snap=exact \
+       1234.cast
+
+   Remove everything between 1m05s and 1m30s, including the frames
+   right around those times.
+
+     asciinema-edit cut \
+       --start=1:05 --end=1m30s \
+       --snap=enclose \
+       1234.cast
+
+   Remove three pieces of a cast at once.
+
+     asciinema-edit cut \
+       --range 0:12,0:15.5 \
+       --range 1:05,1:30 \
+       --range @outtake,@retake \
+       1234.cast
+
+   Remove a minute of an htop session, keeping the screen consistent.
+
+     asciinema-edit cut \
+       --start=2:00 --end=3:00 \
+       --preserve \
        1234.cast`,
 	ArgsUsage: "[filename]",
 	Action:    cutAction,
 	Flags: []cli.Flag{
-		cli.Float64Flag{
+		cli.StringFlag{
 			Name:  "start",
-			Usage: "initial frame timestamp (required)",
+			Usage: "initial frame timestamp (required unless --range is set)",
 		},
-		cli.Float64Flag{
+		cli.StringFlag{
 			Name:  "end",
-			Usage: "final frame timestamp (required)",
+			Usage: "final frame timestamp (required unless --range is set)",
+		},
+		cli.StringSliceFlag{
+			Name:  "range",
+			Usage: "range of frames to remove, as 'start,end' (can be repeated)",
+		},
+		snapFlag,
+		cli.BoolFlag{
+			Name:  "preserve",
+			Usage: "keep the resizes, markers and screen contents of the removed frames",
 		},
 		cli.StringFlag{
 			Name:  "out",
 			Usage: "file to write the modified contents to",
 		},
+		outputVersionFlag,
 	},
 }
 
 type cutTransformation struct {
-	from float64
-	to   float64
+	span   cast.Range
+	policy cast.SnapPolicy
 }
 
 func (t *cutTransformation) Transform(c *cast.Cast) (err error) {
-	err = cast.Cut(c, t.from, t.to)
+	err = cast.CutRange(c, t.span, t.policy)
+	return
+}
+
+func (t *cutTransformation) TransformStream(src cast.EventSource, dst cast.EventSink) (err error) {
+	err = cast.CutStream(src, dst, t.span, t.policy)
+	return
+}
+
+// cutRangesTransformation removes several ranges at once, or redraws
+// the screen after the cut, which both need the whole cast (so it has
+// no streaming variant).
+type cutRangesTransformation struct {
+	spans    []cast.Range
+	policy   cast.SnapPolicy
+	preserve bool
+}
+
+func (t *cutRangesTransformation) Transform(c *cast.Cast) (err error) {
+	if t.preserve {
+		err = cast.CutRangesPreserving(c, t.spans, t.policy, vt.Restore)
+		return
+	}
+
+	err = cast.CutRanges(c, t.spans, t.policy)
 	return
 }
 
@@ -63,12 +134,51 @@ func cutAction(c *cli.Context) (err error) {
 	var (
 		input          = c.Args().First()
 		output         = c.String("out")
-		transformation = &cutTransformation{
-			from: c.Float64("start"),
-			to:   c.Float64("end"),
-		}
+		transformation transformer.Transformation
+		spans          []cast.Range
+		policy         cast.SnapPolicy
 	)
 
+	ranges, err := parseRangesFlag(c, false, 0)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if len(ranges) == 0 {
+		var span cast.Range
+
+		span, policy, err = parseRangeFlags(c, nil, nil)
+		spans = append(spans, span)
+	} else {
+		for _, r := range ranges {
+			spans = append(spans, r.Range)
+		}
+
+		policy, err = cast.ParseSnapPolicy(c.String("snap"))
+	}
+
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if len(spans) == 1 && !c.Bool("preserve") {
+		transformation = &cutTransformation{span: spans[0], policy: policy}
+	} else {
+		transformation = &cutRangesTransformation{
+			spans:    spans,
+			policy:   policy,
+			preserve: c.Bool("preserve"),
+		}
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	t, err := transformer.New(transformation, input, output)
 	if err != nil {
 		err = cli.NewExitError(err, 1)
@@ -76,6 +186,12 @@ func cutAction(c *cli.Context) (err error) {
 	}
 	defer t.Close()
 
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	err = t.Transform()
 	if err != nil {
 		err = cli.NewExitError(err, 1)
@@ -0,0 +1,270 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+	"github.com/wormbks/asciinema-edit/vt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Export = cli.Command{
+	Name: "export",
+	Usage: `Exports a cast to other formats.
+
+   The cast is played into a virtual terminal of the size declared in
+   its header (following any resize events) and every distinct state
+   of the screen is rendered, using the theme declared in the header
+   (if any). Pauses longer than the header's 'idle_time_limit' are
+   shortened to it, as players do.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   The result is either written to a file specified in the '--out'
+   flag or to stdout (default).`,
+	Subcommands: []cli.Command{
+		exportSVG,
+		exportGIF,
+		exportHTML,
+	},
+}
+
+var exportSVG = cli.Command{
+	Name: "svg",
+	Usage: `Exports a cast as a self-contained animated SVG.
+
+   Each distinct screen is drawn once and CSS keyframes bring the
+   right one into view at the time of every frame, looping forever.
+
+EXAMPLES:
+   Export a cast to be embedded in a README:
+
+     asciinema-edit export svg --out demo.svg 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    exportSVGAction,
+	Flags: []cli.Flag{
+		loopDelayFlag,
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the svg document to",
+		},
+	},
+}
+
+var exportGIF = cli.Command{
+	Name: "gif",
+	Usage: `Exports a cast as an animated GIF.
+
+   The screen is rasterized with a built-in bitmap font after each
+   event (at most 50 times per second, or '--fps' if lower), with
+   frame delays following the time between events. The animation
+   loops forever.
+
+EXAMPLES:
+   Export a cast to be attached to an issue:
+
+     asciinema-edit export gif --out demo.gif 1234.cast
+
+   Export a smaller GIF, capped at 10 frames per second:
+
+     asciinema-edit export gif --fps 10 --scale 1 --out demo.gif 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    exportGIFAction,
+	Flags: []cli.Flag{
+		loopDelayFlag,
+		cli.Float64Flag{
+			Name:  "fps",
+			Usage: "maximum number of frames per second (defaults to 50)",
+		},
+		cli.IntFlag{
+			Name:  "scale",
+			Usage: "size of the pixels of the font",
+			Value: 2,
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the gif image to",
+		},
+	},
+}
+
+var exportHTML = cli.Command{
+	Name: "html",
+	Usage: `Exports a cast as a self-contained HTML page with a player.
+
+   The page embeds every distinct screen along with a small player
+   offering play/pause, a seek bar, speed control and chapters made
+   of the markers of the cast. It doesn't load anything from the
+   network, so it can be opened offline by anyone with a browser.
+
+EXAMPLES:
+   Export a cast to be sent to someone without asciinema installed:
+
+     asciinema-edit export html --out demo.html 1234.cast
+
+   Export a cast, dropping frames to keep the page smaller:
+
+     asciinema-edit export html --fps 10 --out demo.html 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    exportHTMLAction,
+	Flags: []cli.Flag{
+		cli.Float64Flag{
+			Name:  "fps",
+			Usage: "maximum number of frames per second (defaults to no limit)",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the html page to",
+		},
+	},
+}
+
+var loopDelayFlag = cli.Float64Flag{
+	Name:  "loop-delay",
+	Usage: "seconds to hold the last frame for before looping",
+	Value: 1,
+}
+
+// exportFrames reads the cast `input`, renders every distinct state of
+// its screen and hands the frames to the encoder created by
+// `newEncoder`, which writes them to `output`.
+func exportFrames(c *cli.Context, opts render.Options, newEncoder func(w io.Writer, c *cast.Cast, opts render.Options) render.FrameEncoder) (err error) {
+	var (
+		input  = c.Args().First()
+		output = c.String("out")
+	)
+
+	if c.Float64("loop-delay") < 0 {
+		err = cli.NewExitError(errors.Errorf("--loop-delay must not be negative"), 1)
+		return
+	}
+
+	decoded, err := readCast(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	opts.Theme, err = render.ThemeFromHeader(decoded.Header.Theme)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	opts.LoopDelay = c.Float64("loop-delay")
+
+	err = limitIdleTime(decoded)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	out, err := createOutput(output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer out.Close()
+
+	encoder := newEncoder(out, decoded, opts)
+
+	err = vt.Frames(decoded, encoder.WriteFrame)
+	if err == nil {
+		err = encoder.Close()
+	}
+
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to export cast"), 1)
+		return
+	}
+
+	return
+}
+
+// limitIdleTime shortens the pauses of a cast that are longer than the
+// idle time limit declared in its header, the one before the first
+// event included.
+func limitIdleTime(c *cast.Cast) error {
+	limit := cast.Seconds(c.Header.IdleTimeLimit)
+	if limit <= 0 || len(c.EventStream) == 0 {
+		return nil
+	}
+
+	err := cast.Quantize(c, []cast.QuantizeRange{
+		{From: limit, To: cast.MaxTime},
+	})
+	if err != nil {
+		return err
+	}
+
+	// `Quantize` leaves the time before the first event alone.
+	if shift := c.EventStream[0].Time - limit; shift > 0 {
+		for _, ev := range c.EventStream {
+			ev.Time -= shift
+		}
+	}
+
+	return nil
+}
+
+func exportSVGAction(c *cli.Context) error {
+	return exportFrames(c, render.Options{}, func(w io.Writer, _ *cast.Cast, opts render.Options) render.FrameEncoder {
+		return render.NewSVG(w, opts)
+	})
+}
+
+func exportGIFAction(c *cli.Context) error {
+	opts := render.Options{
+		Scale: c.Int("scale"),
+		FPS:   c.Float64("fps"),
+	}
+
+	if opts.FPS < 0 {
+		return cli.NewExitError(errors.Errorf("--fps must not be negative"), 1)
+	}
+
+	return exportFrames(c, opts, func(w io.Writer, _ *cast.Cast, opts render.Options) render.FrameEncoder {
+		return render.NewGIF(w, opts)
+	})
+}
+
+func exportHTMLAction(c *cli.Context) error {
+	opts := render.Options{
+		FPS: c.Float64("fps"),
+	}
+
+	if opts.FPS < 0 {
+		return cli.NewExitError(errors.Errorf("--fps must not be negative"), 1)
+	}
+
+	return exportFrames(c, opts, func(w io.Writer, decoded *cast.Cast, opts render.Options) render.FrameEncoder {
+		return render.NewHTML(w, opts, htmlInfo(decoded))
+	})
+}
+
+// htmlInfo gathers the title, duration and chapters (one per marker) of
+// the page of a cast.
+func htmlInfo(c *cast.Cast) render.HTMLInfo {
+	info := render.HTMLInfo{
+		Title: c.Header.Title,
+	}
+
+	for _, ev := range c.EventStream {
+		info.Duration = ev.Time
+
+		if ev.Type == "m" {
+			label := ev.Data
+			if label == "" {
+				label = fmt.Sprintf("marker %d", len(info.Chapters)+1)
+			}
+
+			info.Chapters = append(info.Chapters, render.Chapter{Time: ev.Time, Label: label})
+		}
+	}
+
+	return info
+}
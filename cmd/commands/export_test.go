@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+func TestLimitIdleTime(t *testing.T) {
+	newCast := func(limit float64, times ...float64) *cast.Cast {
+		c := &cast.Cast{Header: cast.Header{Version: 2, IdleTimeLimit: limit}}
+		for _, time := range times {
+			c.EventStream = append(c.EventStream, &cast.Event{Time: cast.Seconds(time), Type: "o"})
+		}
+		return c
+	}
+
+	times := func(c *cast.Cast) (res []cast.Time) {
+		for _, ev := range c.EventStream {
+			res = append(res, ev.Time)
+		}
+		return
+	}
+
+	t.Run("Shortens pauses longer than the limit", func(t *testing.T) {
+		c := newCast(2, 1, 1.5, 6.5, 7)
+		assert.NoError(t, limitIdleTime(c))
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(1), cast.Seconds(1.5), cast.Seconds(3.5), cast.Seconds(4),
+		}, times(c))
+	})
+
+	t.Run("Shortens a long initial idle", func(t *testing.T) {
+		c := newCast(2, 10, 10.5, 20)
+		assert.NoError(t, limitIdleTime(c))
+		assert.Equal(t, []cast.Time{
+			cast.Seconds(2), cast.Seconds(2.5), cast.Seconds(4.5),
+		}, times(c))
+	})
+
+	t.Run("Does nothing without a limit", func(t *testing.T) {
+		c := newCast(0, 10, 20)
+		assert.NoError(t, limitIdleTime(c))
+		assert.Equal(t, []cast.Time{cast.Seconds(10), cast.Seconds(20)}, times(c))
+	})
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"github.com/wormbks/asciinema-edit/vt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Extract = cli.Command{
+	Name: "extract",
+	Usage: `Keeps only a certain range of time frames.
+
+   Everything before '--start' and after '--end' is removed, and the
+   remaining frames are moved so that the cast starts at zero.
+
+   So that the cast doesn't start from a blank screen, the screen as it
+   was at '--start' (contents, colors, cursor, alternate screen, ...) is
+   redrawn by an output event at its very beginning, unless
+   '--no-redraw' is set. The header gets the terminal size at that
+   point as well.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default).
+` + positionUsage + `
+
+EXAMPLES:
+   Keep minutes 3 to 5 of a recording:
+
+     asciinema-edit extract \
+       --start 3:00 --end 5:00 \
+       --out excerpt.cast \
+       1234.cast
+
+   Keep the chapter between two markers:
+
+     asciinema-edit extract \
+       --start @install --end @usage \
+       --snap within \
+       1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    extractAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "start",
+			Usage: "initial frame timestamp (required)",
+		},
+		cli.StringFlag{
+			Name:  "end",
+			Usage: "final frame timestamp (required)",
+		},
+		snapFlag,
+		cli.BoolFlag{
+			Name:  "no-redraw",
+			Usage: "don't redraw the screen as it was at the initial frame",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type extractTransformation struct {
+	span    cast.Range
+	policy  cast.SnapPolicy
+	restore cast.Restorer
+}
+
+func (t *extractTransformation) Transform(c *cast.Cast) (err error) {
+	err = cast.ExtractRange(c, t.span, t.policy, t.restore)
+	return
+}
+
+func extractAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().First()
+		output         = c.String("out")
+		transformation = &extractTransformation{}
+	)
+
+	transformation.span, transformation.policy, err = parseRangeFlags(c, nil, nil)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if !c.Bool("no-redraw") {
+		transformation.restore = vt.Restore
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const positionSyntaxUsage = `
+   Timestamps can be given in seconds (12.2), as '[hh:]mm:ss[.fff]'
+   (1:23.5), as durations (1m23s), as event indexes (#42, #-1 being
+   the last event) or as marker labels (@intro, the first marker event
+   with that label).`
+
+const positionUsage = positionSyntaxUsage + `
+
+   Timestamps that don't match an event are snapped to one according
+   to '--snap':
+
+      exact     only events with that exact timestamp match;
+      nearest   the closest event is picked (default);
+      enclose   the range grows to the events around the timestamps;
+      within    the range shrinks to the events inside the timestamps.`
+
+var outputVersionFlag = cli.IntFlag{
+	Name:  "output-version",
+	Usage: "asciicast version of the output (2 or 3, defaults to the input's)",
+}
+
+// parseOutputVersionFlag parses `--output-version` (see
+// `outputVersionFlag`), 0 standing for the version of the input.
+func parseOutputVersionFlag(c *cli.Context) (version uint8, err error) {
+	value := c.Int("output-version")
+	if value != 0 && value != 2 && value != 3 {
+		err = errors.Errorf("invalid --output-version %d: must be either 2 or 3", value)
+		return
+	}
+
+	version = uint8(value)
+	return
+}
+
+var snapFlag = cli.StringFlag{
+	Name:  "snap",
+	Usage: "how timestamps are snapped to events (exact, nearest, enclose or within)",
+	Value: cast.SnapNearest.String(),
+}
+
+// parsePositionFlag parses the flag `name` as a position, falling back
+// to `def` when the flag hasn't been set.
+func parsePositionFlag(c *cli.Context, name string, def *cast.Position) (res cast.Position, err error) {
+	input := c.String(name)
+
+	if input == "" {
+		if def == nil {
+			err = errors.Errorf("flag --%s must be specified", name)
+			return
+		}
+
+		res = *def
+		return
+	}
+
+	res, err = cast.ParsePosition(input)
+	if err != nil {
+		err = errors.Wrapf(err, "invalid --%s", name)
+		return
+	}
+
+	return
+}
+
+// parseRangeFlags parses the `--start`, `--end` and `--snap` flags.
+//
+// When `defFrom` or `defTo` are nil, the corresponding flag is required.
+func parseRangeFlags(c *cli.Context, defFrom, defTo *cast.Position) (r cast.Range, policy cast.SnapPolicy, err error) {
+	r.From, err = parsePositionFlag(c, "start", defFrom)
+	if err != nil {
+		return
+	}
+
+	r.To, err = parsePositionFlag(c, "end", defTo)
+	if err != nil {
+		return
+	}
+
+	policy, err = cast.ParseSnapPolicy(c.String("snap"))
+	return
+}
+
+// parseRangesFlag parses the repeatable `--range` flag, whose values are
+// written as `from,to` or, if `withFactor` is set, as `from,to[,factor]`
+// (the factor defaulting to `factor`).
+//
+// The flag can't be combined with `--start` and `--end`.
+func parseRangesFlag(c *cli.Context, withFactor bool, factor float64) (ranges []cast.FactorRange, err error) {
+	values := c.StringSlice("range")
+	if len(values) == 0 {
+		return
+	}
+
+	if c.String("start") != "" || c.String("end") != "" {
+		err = errors.Errorf("--range can't be combined with --start and --end")
+		return
+	}
+
+	for _, value := range values {
+		var (
+			r     = cast.FactorRange{Factor: factor}
+			parts = strings.Split(value, ",")
+		)
+
+		if withFactor && len(parts) > 3 {
+			err = errors.Errorf("--range '%s' must be written as 'from,to[,factor]'", value)
+			return
+		}
+
+		if withFactor && len(parts) == 3 {
+			r.Factor, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+			if err != nil {
+				err = errors.Errorf("invalid factor in --range '%s'", value)
+				return
+			}
+
+			parts = parts[:2]
+		}
+
+		r.Range, err = cast.ParseRange(strings.Join(parts, ","))
+		if err != nil {
+			err = errors.Wrapf(err, "invalid --range")
+			return
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"flag"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/urfave/cli.v1"
+)
+
+func TestParseOutputVersionFlag(t *testing.T) {
+	parse := func(value int) (uint8, error) {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		outputVersionFlag.Apply(set)
+		assert.NoError(t, set.Parse([]string{"--output-version", strconv.Itoa(value)}))
+
+		return parseOutputVersionFlag(cli.NewContext(nil, set, nil))
+	}
+
+	for _, value := range []int{0, 2, 3} {
+		version, err := parse(value)
+		assert.NoError(t, err)
+		assert.Equal(t, uint8(value), version)
+	}
+
+	for _, value := range []int{1, 4, -1, 258} {
+		_, err := parse(value)
+		assert.Error(t, err, "version %d", value)
+	}
+}
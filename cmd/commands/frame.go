@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+	"github.com/wormbks/asciinema-edit/vt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Frame = cli.Command{
+	Name: "frame",
+	Usage: `Renders the terminal screen at a given point of a cast.
+
+   The cast is played into a virtual terminal of the size declared in
+   its header (following any resize events) up to the point specified
+   in '--at', which can be a timestamp, an event index or a marker
+   (@label).
+
+   The screen is rendered according to '--format':
+
+      text   plain text, without trailing blanks (default);
+      ansi   text with the escape sequences that reproduce colors and
+             attributes; and
+      png    an image drawn with a built-in bitmap font and the theme
+             declared in the cast header (if any).
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   The result is either written to a file specified in the '--out'
+   flag or to stdout (default).
+` + positionSyntaxUsage + `
+
+EXAMPLES:
+   Print the screen 12.5 seconds into the recording:
+
+     asciinema-edit frame --at 12.5 1234.cast
+
+   Save a screenshot of the screen at the marker labeled 'demo':
+
+     asciinema-edit frame \
+       --at @demo \
+       --format png \
+       --out demo.png \
+       1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    frameAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "at",
+			Usage: "timestamp, event index or marker to render (required)",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "output format (text, ansi or png)",
+			Value: "text",
+		},
+		cli.IntFlag{
+			Name:  "scale",
+			Usage: "size of the pixels of the font (png)",
+			Value: 2,
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the rendered frame to",
+		},
+	},
+}
+
+// writeFrame renders a snapshot of the terminal in the format `format`.
+func writeFrame(w io.Writer, snapshot *vt.Snapshot, header *cast.Header, format string, scale int) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, snapshot.Text()+"\n")
+		return err
+	case "ansi":
+		_, err := io.WriteString(w, snapshot.ANSI()+"\n")
+		return err
+	case "png":
+		theme, err := render.ThemeFromHeader(header.Theme)
+		if err != nil {
+			return err
+		}
+
+		return render.EncodePNG(w, snapshot, render.Options{
+			Theme: theme,
+			Scale: scale,
+		})
+	default:
+		return errUnknownFormat(format)
+	}
+}
+
+func errUnknownFormat(format string) error {
+	return errors.Errorf(
+		"unknown format '%s': must be one of text, ansi, png", format)
+}
+
+func frameAction(c *cli.Context) (err error) {
+	var (
+		input  = c.Args().First()
+		output = c.String("out")
+		format = c.String("format")
+	)
+
+	at, err := parsePositionFlag(c, "at", nil)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if format != "text" && format != "ansi" && format != "png" {
+		err = cli.NewExitError(errUnknownFormat(format), 1)
+		return
+	}
+
+	decoded, err := readCast(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := cast.ResolveTime(decoded, at)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	snapshot, err := vt.Replay(decoded, t)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	out, err := createOutput(output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer out.Close()
+
+	err = writeFrame(out, snapshot, &decoded.Header, format, c.Int("scale"))
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to render frame"), 1)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Info = cli.Command{
+	Name: "info",
+	Usage: `Reports statistics about a cast.
+
+   The report covers:
+
+      - the version and initial size of the terminal;
+      - the total duration (timestamp of the last event);
+      - the number of events of each type ('o', 'i', 'r', 'm'...);
+      - the number of bytes of output and of resizes;
+      - a histogram of the gaps between events; and
+      - the longest pauses (as many as '--top'), with the time they
+        start at and the index of the event that ends them.
+
+   With '--json', the report is written as a JSON object instead,
+   with durations in seconds.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+EXAMPLES:
+   Summarize a cast:
+
+     asciinema-edit info 1234.cast
+
+   Reject casts longer than 5 minutes in CI:
+
+     test "$(asciinema-edit info --json 1234.cast | jq '.duration < 300')" = true
+
+   List the 20 longest pauses, to pick quantization ranges:
+
+     asciinema-edit info --top 20 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    infoAction,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "json",
+			Usage: "write the report as JSON",
+		},
+		cli.IntFlag{
+			Name:  "top",
+			Usage: "number of longest pauses to list",
+			Value: 5,
+		},
+	},
+}
+
+// writeInfo writes a report of the statistics of a cast meant to be
+// read by humans.
+func writeInfo(w io.Writer, stats *cast.Stats) error {
+	out := bufio.NewWriter(w)
+
+	types := make([]string, 0, len(stats.EventTypes))
+	for typ := range stats.EventTypes {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	counts := make([]string, len(types))
+	for idx, typ := range types {
+		counts[idx] = fmt.Sprintf("%s: %d", typ, stats.EventTypes[typ])
+	}
+
+	fmt.Fprintf(out, "version:      %d\n", stats.Version)
+	fmt.Fprintf(out, "size:         %dx%d\n", stats.Width, stats.Height)
+	fmt.Fprintf(out, "duration:     %s\n", formatSeconds(stats.Duration))
+	fmt.Fprintf(out, "events:       %d", stats.Events)
+	if len(counts) > 0 {
+		fmt.Fprintf(out, " (%s)", strings.Join(counts, ", "))
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "output bytes: %d\n", stats.OutputBytes)
+	fmt.Fprintf(out, "resizes:      %d\n", stats.Resizes)
+
+	fmt.Fprintln(out, "\nidle gaps:")
+	for _, bucket := range stats.Gaps {
+		bounds := formatSeconds(bucket.From) + " - " + formatSeconds(bucket.To)
+		if bucket.To == 0 {
+			bounds = formatSeconds(bucket.From) + " and more"
+		}
+
+		fmt.Fprintf(out, "  %-14s %6d  (%s in total)\n",
+			bounds, bucket.Count, formatSeconds(bucket.Total))
+	}
+
+	if len(stats.LongestPauses) > 0 {
+		fmt.Fprintln(out, "\nlongest pauses:")
+	}
+	for _, pause := range stats.LongestPauses {
+		fmt.Fprintf(out, "  %-10s at %s (before %s)\n",
+			formatSeconds(pause.Duration),
+			cast.TimeAt(pause.Time), cast.EventAt(pause.Index))
+	}
+
+	return out.Flush()
+}
+
+// formatSeconds formats a duration in seconds with at most three
+// decimal places.
+func formatSeconds(t cast.Time) string {
+	return strconv.FormatFloat(math.Round(t.Seconds()*1000)/1000, 'f', -1, 64) + "s"
+}
+
+func infoAction(c *cli.Context) (err error) {
+	input := c.Args().First()
+
+	decoded, err := readCast(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	stats, err := cast.ComputeStats(decoded, c.Int("top"))
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if c.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(stats)
+	} else {
+		err = writeInfo(os.Stdout, stats)
+	}
+
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to write report"), 1)
+		return
+	}
+
+	return
+}
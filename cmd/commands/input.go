@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// openInput opens the file `input`, falling back to stdin if no file
+// name is given.
+func openInput(input string) (io.ReadCloser, error) {
+	if input == "" {
+		return nopReadCloser{os.Stdin}, nil
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to open input file %s", input)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err,
+			"failed to retrieve info about input file %s", input)
+	}
+
+	if stat.IsDir() {
+		file.Close()
+		return nil, errors.Errorf("input file %s is a directory", input)
+	}
+
+	return file, nil
+}
+
+// nopReadCloser keeps stdin open when the input is closed.
+type nopReadCloser struct {
+	io.Reader
+}
+
+func (nopReadCloser) Close() error {
+	return nil
+}
+
+// readCast decodes and validates the cast stored in the file `input`,
+// reading from stdin if no file name is given.
+func readCast(input string) (c *cast.Cast, err error) {
+	reader, err := openInput(input)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	c, err = cast.Decode(reader)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to decode cast from input")
+		return
+	}
+
+	err = c.Validate()
+	if err != nil {
+		err = errors.Wrapf(err,
+			"invalid input cast")
+		return
+	}
+
+	return
+}
+
+// nopCloser keeps stdout open when the output is closed.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// createOutput creates the file `output`, falling back to stdout if no
+// file name is given.
+func createOutput(output string) (io.WriteCloser, error) {
+	if output == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"failed to open output file %s", output)
+	}
+
+	return file, nil
+}
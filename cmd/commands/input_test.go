@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenInput(t *testing.T) {
+	t.Run("Fails if it doesn't exist", func(t *testing.T) {
+		_, err := openInput("/inexistent/1234.cast")
+		assert.Error(t, err)
+	})
+
+	t.Run("Fails if is a directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := openInput(dir)
+		assert.EqualError(t, err, "input file "+dir+" is a directory")
+
+		_, err = readCast(dir)
+		assert.Contains(t, err.Error(), "is a directory")
+	})
+}
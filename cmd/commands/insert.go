@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Insert = cli.Command{
+	Name: "insert",
+	Usage: `Splices a cast into another one at a given time.
+
+   The events of the inserted cast are placed at '--at', right after
+   the events happening at that time, and everything that comes later
+   is delayed by the duration of the inserted cast (markers included).
+
+   When the inserted cast was recorded with another terminal size,
+   resize ('r') events are added so that it renders at its own size,
+   and the size is restored once it ends.
+
+   '--at' can be given in seconds (12.2), as '[hh:]mm:ss[.fff]' (1:23.5),
+   as a duration (1m23s), as an event index (#42) or as a marker label
+   (@intro).
+
+   If no file name is specified after the inserted cast, the cast to
+   insert into is expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default).
+
+EXAMPLES:
+   Add a step recorded apart, 'step3.cast', to a tutorial right where
+   its 'step-3' marker is:
+
+     asciinema-edit insert \
+       --at @step-3 \
+       --out fixed.cast \
+       step3.cast tutorial.cast
+
+   Insert a cast 1 minute and 5 seconds into the cast served via stdin:
+
+     cat tutorial.cast | \
+       asciinema-edit insert --at 1:05 step3.cast`,
+	ArgsUsage: "inserted [filename]",
+	Action:    insertAction,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "at",
+			Usage: "time to insert the cast at (required)",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type insertTransformation struct {
+	other *cast.Cast
+	at    cast.Position
+}
+
+func (t *insertTransformation) Transform(c *cast.Cast) (err error) {
+	err = cast.Insert(c, t.other, t.at)
+	return
+}
+
+func insertAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().Get(1)
+		output         = c.String("out")
+		transformation = &insertTransformation{}
+	)
+
+	if c.NArg() < 1 {
+		err = cli.NewExitError(errors.Errorf("the cast to insert must be specified"), 1)
+		return
+	}
+
+	transformation.at, err = parsePositionFlag(c, "at", nil)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	transformation.other, err = readCast(c.Args().First())
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Lint = cli.Command{
+	Name: "lint",
+	Usage: `Reports every problem found in a cast, with its line number.
+
+   Unlike the other commands, which stop at the first problem, every
+   line of the cast is checked. Problems are reported one per line, as
+   'FILE:LINE: SEVERITY: MESSAGE', the line being left out when it
+   can't be told (v1 casts are a single JSON document).
+
+   Errors make the cast invalid:
+
+      - malformed header or event lines;
+      - unsupported versions and missing dimensions;
+      - unknown event types, and exit status ('x') events outside of v3;
+      - negative or out-of-order times; and
+      - resize events whose data isn't 'COLSxROWS'.
+
+   Warnings point at likely mistakes:
+
+      - invalid UTF-8;
+      - header keys that aren't part of the format;
+      - markers without a label; and
+      - output lines longer than the width of the terminal.
+
+   The command fails if any error is found (or any warning, with
+   '--strict').
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+EXAMPLES:
+   Check a cast before publishing it:
+
+     asciinema-edit lint 1234.cast
+
+   Fail a CI job on warnings as well:
+
+     asciinema-edit lint --strict 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    lintAction,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "strict",
+			Usage: "fail on warnings as well",
+		},
+	},
+}
+
+// writeProblems writes a line per problem, prefixed with the name of
+// the cast.
+func writeProblems(w io.Writer, name string, problems []cast.Problem) error {
+	out := bufio.NewWriter(w)
+
+	for _, problem := range problems {
+		if problem.Line == 0 {
+			fmt.Fprintf(out, "%s: %s\n", name, problem)
+		} else {
+			fmt.Fprintf(out, "%s:%s\n", name, problem)
+		}
+	}
+
+	return out.Flush()
+}
+
+func lintAction(c *cli.Context) (err error) {
+	var (
+		input = c.Args().First()
+		name  = input
+	)
+
+	if name == "" {
+		name = "<stdin>"
+	}
+
+	in, err := openInput(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer in.Close()
+
+	problems, err := cast.Lint(in)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = writeProblems(os.Stdout, name, problems)
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to write problems"), 1)
+		return
+	}
+
+	var errorCount, warningCount int
+	for _, problem := range problems {
+		if problem.Severity == cast.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if errorCount > 0 || (c.Bool("strict") && warningCount > 0) {
+		err = cli.NewExitError(errors.Errorf(
+			"found %d error(s) and %d warning(s)", errorCount, warningCount), 1)
+		return
+	}
+
+	return
+}
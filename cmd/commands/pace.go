@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Pace = cli.Command{
+	Name: "pace",
+	Usage: `Gives viewers time to read by enforcing minimum delays.
+
+   While 'quantize' shortens delays that are too long, 'pace' lengthens
+   the ones that are too short to follow the cast, shifting whatever
+   comes after them:
+
+     - the output of each command stays on screen for at least
+       '--after-output' seconds before anything else happens;
+     - each prompt stays on screen for at least '--before-input'
+       seconds before the next command starts being typed; and
+     - the final frame is held for at least '--hold' seconds.
+
+   A command is considered finished when a prompt gets drawn (the line
+   the cursor is left at ends like '--prompt', escape sequences aside)
+   or when its output ends with a new line followed by at least '--idle'
+   seconds of inactivity. Setting a delay to 0 disables it.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default). The number of delays that got lengthened is written to
+   stderr.
+
+EXAMPLES:
+   Shorten the long pauses of a demo, then make sure it's not too fast
+   to follow:
+
+     asciinema-edit quantize --range 2 demo.cast | \
+       asciinema-edit pace --out paced.cast
+
+   Leave the output of each command on screen for 3 seconds, with a
+   prompt ending in 'λ ':
+
+     asciinema-edit pace \
+       --after-output 3 \
+       --prompt 'λ $' \
+       demo.cast`,
+	ArgsUsage: "[filename]",
+	Action:    paceAction,
+	Flags: []cli.Flag{
+		cli.Float64Flag{
+			Name:  "after-output",
+			Usage: "minimum seconds after the output of a command",
+			Value: 1,
+		},
+		cli.Float64Flag{
+			Name:  "before-input",
+			Usage: "minimum seconds between a prompt and the next command",
+			Value: 0.5,
+		},
+		cli.Float64Flag{
+			Name:  "hold",
+			Usage: "minimum seconds that the final frame is held",
+			Value: 2,
+		},
+		cli.StringFlag{
+			Name:  "prompt",
+			Usage: "regular expression matching the end of a prompt",
+			Value: cast.DefaultPrompt.String(),
+		},
+		cli.Float64Flag{
+			Name:  "idle",
+			Usage: "seconds of inactivity after which output ending with a new line ends a command",
+			Value: 0.5,
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type paceTransformation struct {
+	opts  cast.PaceOptions
+	count int
+}
+
+func (t *paceTransformation) Transform(c *cast.Cast) (err error) {
+	t.count, err = cast.Pace(c, t.opts)
+	return
+}
+
+func paceAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().First()
+		output         = c.String("out")
+		transformation = &paceTransformation{
+			opts: cast.PaceOptions{
+				AfterOutput: cast.Seconds(c.Float64("after-output")),
+				BeforeInput: cast.Seconds(c.Float64("before-input")),
+				Hold:        cast.Seconds(c.Float64("hold")),
+				Idle:        cast.Seconds(c.Float64("idle")),
+			},
+		}
+	)
+
+	transformation.opts.Prompt, err = regexp.Compile(c.String("prompt"))
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "invalid --prompt"), 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d delay(s) lengthened\n", transformation.count)
+
+	return
+}
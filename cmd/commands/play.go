@@ -61,7 +61,7 @@ func playAction(cc *cli.Context) error {
 	p := &castPlayer{
 		cast:          ct,
 		speed:         cc.Float64("speed"),
-		idleTimeLimit: cc.Float64("idle-time-limit"),
+		idleTimeLimit: cast.Seconds(cc.Float64("idle-time-limit")),
 	}
 	return p.play()
 }
@@ -70,11 +70,11 @@ func playAction(cc *cli.Context) error {
 type castPlayer struct {
 	cast          *cast.Cast
 	speed         float64
-	idleTimeLimit float64
+	idleTimeLimit cast.Time
 }
 
 func (p *castPlayer) play() error {
-	lastTime := float64(0.0)
+	var lastTime cast.Time
 	if err := p.cast.Validate(); err != nil {
 		return err
 	}
@@ -113,7 +113,7 @@ func (p *castPlayer) play() error {
 
 // playEvent plays a cast event, introducing a delay before playing if needed.
 // It dispatches to playOutput or resizeTerm based on the event type.
-func (p *castPlayer) playEvent(ev *cast.Event, delay float64) error {
+func (p *castPlayer) playEvent(ev *cast.Event, delay cast.Time) error {
 	switch ev.Type {
 	case "o":
 		return p.playOutput(ev, delay)
@@ -127,24 +127,24 @@ func (p *castPlayer) playEvent(ev *cast.Event, delay float64) error {
 // playOutput plays a cast output event by writing the output to stdout after
 // introducing a delay. It unescapes the output data, sleeps for the specified
 // delay, and then writes the output to stdout.
-func (p *castPlayer) playOutput(ev *cast.Event, delay float64) error {
+func (p *castPlayer) playOutput(ev *cast.Event, delay cast.Time) error {
 	buf, err := unescapeString(ev.Data)
 	if err != nil {
 		return err
 	}
-	time.Sleep(time.Duration(delay * float64(time.Second)))
+	time.Sleep(delay.Duration())
 
 	_, err = os.Stdout.Write(buf)
 	return err
 }
 
-func (p *castPlayer) resizeTerm(ev *cast.Event, delay float64) error {
+func (p *castPlayer) resizeTerm(ev *cast.Event, delay cast.Time) error {
 	_ = ev
 	// width, heigh, err := parseSize(ev.Data)
 	// if err != nil {
 	// 	return err
 	// }
-	time.Sleep(time.Duration(delay * float64(time.Second)))
+	time.Sleep(delay.Duration())
 	// winSize := &pty.Winsize{
 	// 	Rows: uint16(width),
 	// 	Cols: uint16(heigh),
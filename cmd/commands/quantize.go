@@ -1,8 +1,6 @@
 package commands
 
 import (
-	"math"
-	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -36,12 +34,26 @@ var Quantize = cli.Command{
       delta = 1.000000 | qdelta = 1.000000
       delta = 1.000000 | qdelta = 1.000000
 
+   Ranges must not overlap, so that each delay is only reduced by one
+   of them.
+
+   Quantization can be limited to a section of the cast with '--start'
+   and '--end' (the events that come after it are moved accordingly).
+   When only one end is specified, the other one defaults to the first
+   (or last) event.
+
+   It can also be limited to the delays that end with events of some
+   types with '--before': 'i' only touches the delays before input
+   (the typing rhythm), while 'o' only touches the delays before output
+   (such as the time a command takes to run).
+
    If no file name is specified as a positional argument, a cast is
    expected to be serverd via stdin.
 
    Once the transformation has been performed, the resulting cast is
    either written to a file specified in the '--out' flag or to stdout
    (default).
+` + positionUsage + `
 
 EXAMPLES:
    Make the whole cast have a maximum delay of 1s:
@@ -56,6 +68,23 @@ EXAMPLES:
        --range 0.3,1 \
        --range 1,2 \
        --range 2 \
+       ./123.cast
+
+   Make typing look steady without touching the time commands take to
+   run:
+
+     asciinema-edit quantize \
+       --range 0.1,1 \
+       --range 1 \
+       --before i \
+       ./123.cast
+
+   Only shorten the pauses between the 'install' and 'usage' markers:
+
+     asciinema-edit quantize \
+       --range 2 \
+       --start @install \
+       --end @usage \
        ./123.cast`,
 	ArgsUsage: "[filename]",
 	Action:    quantizeAction,
@@ -64,19 +93,39 @@ EXAMPLES:
 			Name:  "range",
 			Usage: "quantization ranges (comma delimited)",
 		},
+		cli.StringFlag{
+			Name:  "start",
+			Usage: "initial frame timestamp",
+		},
+		cli.StringFlag{
+			Name:  "end",
+			Usage: "final frame timestamp",
+		},
+		snapFlag,
+		cli.StringFlag{
+			Name:  "before",
+			Usage: "only quantize the delays before events of these types (comma delimited, e.g. 'i' or 'o')",
+		},
 		cli.StringFlag{
 			Name:  "out",
 			Usage: "file to write the modified contents to",
 		},
+		outputVersionFlag,
 	},
 }
 
 type quantizeTransformation struct {
 	ranges []cast.QuantizeRange
+	scope  cast.QuantizeScope
 }
 
 func (t *quantizeTransformation) Transform(c *cast.Cast) (err error) {
-	err = cast.Quantize(c, t.ranges)
+	err = cast.QuantizeScoped(c, t.ranges, t.scope)
+	return
+}
+
+func (t *quantizeTransformation) TransformStream(src cast.EventSource, dst cast.EventSink) (err error) {
+	err = cast.QuantizeScopedStream(src, dst, t.ranges, t.scope)
 	return
 }
 
@@ -101,23 +150,23 @@ func ParseQuantizeRange(input string) (res cast.QuantizeRange, err error) {
 	}
 
 	if len(cols) == 2 {
-		res.To, err = strconv.ParseFloat(cols[1], 64)
+		res.To, err = cast.ParseTime(cols[1])
 		if err != nil {
 			err = errors.Errorf(
-				"malformed range: second element is not a float '%s'", cols[1])
+				"malformed range: second element is not a number '%s'", cols[1])
 			return
 		}
 	}
 
-	res.From, err = strconv.ParseFloat(cols[0], 64)
+	res.From, err = cast.ParseTime(cols[0])
 	if err != nil {
 		err = errors.Errorf(
-			"malformed range: first element is not a float '%s'", cols[0])
+			"malformed range: first element is not a number '%s'", cols[0])
 		return
 	}
 
 	if res.To == 0 {
-		res.To = math.MaxFloat64
+		res.To = cast.MaxTime
 	}
 
 	if res.From < 0 {
@@ -154,6 +203,28 @@ func parseQuantizeRanges(inputs []string) (ranges []cast.QuantizeRange, err erro
 		ranges = append(ranges, qRange)
 	}
 
+	err = cast.ValidateQuantizeRanges(ranges)
+	return
+}
+
+// parseEventTypes parses a comma delimited list of event types.
+func parseEventTypes(input string) (types []string, err error) {
+	if input == "" {
+		return
+	}
+
+	for _, typ := range strings.Split(input, ",") {
+		typ = strings.TrimSpace(typ)
+
+		err = (&cast.Event{Type: typ}).ValidateEvent()
+		if err != nil {
+			err = errors.Errorf("unknown event type '%s'", typ)
+			return
+		}
+
+		types = append(types, typ)
+	}
+
 	return
 }
 
@@ -162,6 +233,8 @@ func quantizeAction(c *cli.Context) (err error) {
 		input          = c.Args().First()
 		output         = c.String("out")
 		ranges         = c.StringSlice("range")
+		first          = cast.EventAt(0)
+		last           = cast.EventAt(-1)
 		transformation = &quantizeTransformation{}
 	)
 
@@ -176,6 +249,26 @@ func quantizeAction(c *cli.Context) (err error) {
 		return
 	}
 
+	span, policy, err := parseRangeFlags(c, &first, &last)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	transformation.scope = cast.QuantizeScope{Span: &span, Policy: policy}
+
+	transformation.scope.Before, err = parseEventTypes(c.String("before"))
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "invalid --before"), 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	t, err := transformer.New(transformation, input, output)
 	if err != nil {
 		err = cli.NewExitError(err, 1)
@@ -183,6 +276,12 @@ func quantizeAction(c *cli.Context) (err error) {
 	}
 	defer t.Close()
 
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	err = t.Transform()
 	if err != nil {
 		err = cli.NewExitError(err, 1)
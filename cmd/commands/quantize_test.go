@@ -1,10 +1,10 @@
 package commands
 
 import (
-	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wormbks/asciinema-edit/cast"
 )
 
 func TestParseQuantizeRange(t *testing.T) {
@@ -38,8 +38,8 @@ func TestParseQuantizeRange(t *testing.T) {
 		input := "1.2"
 		qRange, err := ParseQuantizeRange(input)
 		assert.NoError(t, err)
-		assert.Equal(t, float64(1.2), qRange.From)
-		assert.Equal(t, math.MaxFloat64, qRange.To)
+		assert.Equal(t, cast.Seconds(1.2), qRange.From)
+		assert.Equal(t, cast.MaxTime, qRange.To)
 
 		input = "-1.2"
 		_, err = ParseQuantizeRange(input)
@@ -63,3 +63,25 @@ func TestParseQuantizeRange(t *testing.T) {
 		}
 	})
 }
+
+func TestParseQuantizeRanges(t *testing.T) {
+	t.Run("Fails with overlapping ranges", func(t *testing.T) {
+		_, err := parseQuantizeRanges([]string{"0.5,2", "1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Accepts adjacent ranges", func(t *testing.T) {
+		ranges, err := parseQuantizeRanges([]string{"0.5,2", "2"})
+		assert.NoError(t, err)
+		assert.Len(t, ranges, 2)
+	})
+}
+
+func TestParseEventTypes(t *testing.T) {
+	types, err := parseEventTypes("i, o")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"i", "o"}, types)
+
+	_, err = parseEventTypes("i,z")
+	assert.Error(t, err)
+}
@@ -254,9 +254,11 @@ func (w *scriptWriter) Begin(size WindowSizeT, shell string) error {
 		Version: 2,
 		Width:   uint(size.cols),
 		Height:  uint(size.rows),
+		Env: map[string]string{
+			"TERM":  os.Getenv("TERM"),
+			"SHELL": shell,
+		},
 	}
-	header.Env.Term = os.Getenv("TERM")
-	header.Env.Shell = shell
 	header.Timestamp = uint(time.Now().Unix())
 	encoder := json.NewEncoder(w.outputFile)
 	encoder.SetIndent("", "")
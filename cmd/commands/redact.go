@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Redact = cli.Command{
+	Name: "redact",
+	Usage: `Masks secrets in the output and input of a cast.
+
+   Secrets are replaced with one mask character per column they take
+   (two for wide characters), so that the layout of the screen is kept.
+   The output ('o') and input ('i') events are searched as a whole, so
+   that secrets split across events (as when typed one key at a time)
+   are found as well; escape sequences and line breaks are never
+   masked.
+
+   Besides the patterns given with '--pattern' (regular expressions in
+   RE2 syntax, masking only the group named 'secret' if there is one),
+   the following detectors are built in (see '--no-builtin'):
+
+      aws-access-key   AWS access key ids (AKIA...);
+      aws-secret-key   AWS secret access keys following their name;
+      github-token     GitHub tokens (ghp_..., github_pat_...);
+      gitlab-token     GitLab tokens (glpat-...);
+      jwt              JSON web tokens;
+      private-key      the body of private key blocks (PEM); and
+      password         values of 'password=', 'token:', 'api_key=' and
+                       the like.
+
+   A report of what got masked (never the secrets themselves) is
+   written to stderr.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default).
+
+EXAMPLES:
+   Scrub a cast before publishing it:
+
+     asciinema-edit redact --out public.cast 1234.cast
+
+   Mask internal host names as well:
+
+     asciinema-edit redact \
+       --pattern '[a-z0-9-]+\.corp\.example\.com' \
+       --out public.cast \
+       1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    redactAction,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "pattern",
+			Usage: "regular expression matching secrets (can be repeated)",
+		},
+		cli.BoolFlag{
+			Name:  "no-builtin",
+			Usage: "only use the patterns given with --pattern",
+		},
+		cli.StringFlag{
+			Name:  "mask",
+			Usage: "character to replace secrets with",
+			Value: "*",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type redactTransformation struct {
+	detectors  []cast.Detector
+	mask       rune
+	redactions []cast.Redaction
+}
+
+func (t *redactTransformation) Transform(c *cast.Cast) (err error) {
+	t.redactions, err = cast.Redact(c, t.detectors, t.mask)
+	return
+}
+
+// parseDetectors builds the detectors to redact secrets with.
+func parseDetectors(patterns []string, builtin bool) (detectors []cast.Detector, err error) {
+	if builtin {
+		detectors = cast.BuiltinDetectors()
+	}
+
+	for idx, pattern := range patterns {
+		var re *regexp.Regexp
+
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid pattern '%s'", pattern)
+			return
+		}
+
+		detectors = append(detectors, cast.Detector{
+			Name:    fmt.Sprintf("pattern-%d", idx+1),
+			Pattern: re,
+		})
+	}
+
+	if len(detectors) == 0 {
+		err = errors.Errorf("at least one pattern must be specified with --no-builtin")
+	}
+
+	return
+}
+
+// writeRedactions writes a line per masked secret.
+func writeRedactions(w io.Writer, redactions []cast.Redaction) {
+	for _, r := range redactions {
+		fmt.Fprintf(w, "masked %d characters of %s in '%s' events at %s (%s)\n",
+			r.Length, r.Detector, r.Type, cast.TimeAt(r.Time), cast.EventAt(r.Index))
+	}
+
+	fmt.Fprintf(w, "%d secret(s) masked\n", len(redactions))
+}
+
+func redactAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().First()
+		output         = c.String("out")
+		mask           = c.String("mask")
+		transformation = &redactTransformation{}
+	)
+
+	if utf8.RuneCountInString(mask) != 1 {
+		err = cli.NewExitError(errors.Errorf("--mask must be a single character"), 1)
+		return
+	}
+
+	transformation.mask, _ = utf8.DecodeRuneInString(mask)
+
+	transformation.detectors, err = parseDetectors(c.StringSlice("pattern"), !c.Bool("no-builtin"))
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	writeRedactions(os.Stderr, transformation.redactions)
+
+	return
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/cmd/commands/transformer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Replace = cli.Command{
+	Name: "replace",
+	Usage: `Replaces a piece of text in the output of a cast.
+
+   The output ('o') events are searched as a whole, so that text split
+   across several events is found, and escape sequences are skipped
+   while matching (text colored halfway through is found as well).
+   The new text is spread over the events that the old text came from,
+   which keep their timestamps: text that was typed one key at a time
+   still shows up the same way.
+
+   The pattern is taken literally unless '--regexp' is set, in which
+   case it is a regular expression (RE2 syntax) and the replacement can
+   refer to its groups ($1, ${name}).
+
+   If no file name is specified after the pattern and the replacement,
+   a cast is expected to be served via stdin.
+
+   Once the transformation has been performed, the resulting cast is
+   either written to a file specified in the '--out' flag or to stdout
+   (default). The number of replacements is written to stderr.
+
+EXAMPLES:
+   Rename a host in the output and in what got typed:
+
+     asciinema-edit replace --input \
+       build-07.internal demo-host \
+       1234.cast
+
+   Bump every version string:
+
+     asciinema-edit replace --regexp \
+       'v1\.2\.(\d+)' 'v1.3.$1' \
+       1234.cast`,
+	ArgsUsage: "pattern replacement [filename]",
+	Action:    replaceAction,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "regexp",
+			Usage: "interpret the pattern as a regular expression",
+		},
+		cli.BoolFlag{
+			Name:  "ignore-case",
+			Usage: "ignore case distinctions",
+		},
+		cli.BoolFlag{
+			Name:  "input",
+			Usage: "replace text in input ('i') events as well",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the modified contents to",
+		},
+		outputVersionFlag,
+	},
+}
+
+type replaceTransformation struct {
+	types       []string
+	pattern     *regexp.Regexp
+	replacement string
+	count       int
+}
+
+func (t *replaceTransformation) Transform(c *cast.Cast) (err error) {
+	for _, evType := range t.types {
+		var count int
+
+		count, err = cast.Replace(c, evType, t.pattern, t.replacement)
+		if err != nil {
+			return
+		}
+
+		t.count += count
+	}
+
+	return
+}
+
+func replaceAction(c *cli.Context) (err error) {
+	var (
+		input          = c.Args().Get(2)
+		output         = c.String("out")
+		transformation = &replaceTransformation{
+			types:       []string{"o"},
+			replacement: c.Args().Get(1),
+		}
+	)
+
+	if c.NArg() < 2 {
+		err = cli.NewExitError(errors.Errorf("a pattern and a replacement must be specified"), 1)
+		return
+	}
+
+	if c.Bool("input") {
+		transformation.types = append(transformation.types, "i")
+	}
+
+	transformation.pattern, err = compilePattern(c.Args().Get(0), c.Bool("regexp"), c.Bool("ignore-case"))
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if !c.Bool("regexp") {
+		// the replacement is taken literally as well.
+		transformation.replacement = strings.ReplaceAll(transformation.replacement, "$", "$$")
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	t, err := transformer.New(transformation, input, output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer t.Close()
+
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = t.Transform()
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d replacement(s)\n", transformation.count)
+
+	return
+}
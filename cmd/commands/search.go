@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Search = cli.Command{
+	Name: "search",
+	Usage: `Finds when a piece of text appeared on the screen.
+
+   The cast is played into a virtual terminal of the size declared in
+   its header (following any resize events) and the screen is searched
+   after every event, so that text split across several events (or
+   drawn with escape sequences in between) is found as soon as it is
+   complete. Text that stays on the screen is only reported once, even
+   if it scrolls.
+
+   Every match is printed as a line holding, separated by tabs:
+
+      - the time of the event that made the text visible, in seconds;
+      - the index of that event (#N); and
+      - the line of the screen holding the text.
+
+   Both the time and the index can be passed to the '--start' and
+   '--end' flags of 'cut' and 'speed'.
+
+   The pattern is taken literally unless '--regexp' is set, in which
+   case it is a regular expression (RE2 syntax). Matches don't span
+   lines, except for lines wrapped at the right margin.
+
+   If no file name is specified after the pattern, a cast is expected
+   to be served via stdin.
+
+EXAMPLES:
+   Find when 'make test' got run:
+
+     asciinema-edit search "make test" 1234.cast
+
+   Find every failing test, ignoring case:
+
+     asciinema-edit search --regexp --ignore-case "^--- fail" 1234.cast`,
+	ArgsUsage: "pattern [filename]",
+	Action:    searchAction,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "regexp",
+			Usage: "interpret the pattern as a regular expression",
+		},
+		cli.BoolFlag{
+			Name:  "ignore-case",
+			Usage: "ignore case distinctions",
+		},
+	},
+}
+
+// compilePattern compiles the pattern to look for, escaping it unless
+// it is a regular expression.
+func compilePattern(pattern string, isRegexp, ignoreCase bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.Errorf("pattern must not be empty")
+	}
+
+	if !isRegexp {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern")
+	}
+
+	return re, nil
+}
+
+// writeMatches writes a line per match.
+func writeMatches(w io.Writer, matches []vt.Match) error {
+	out := bufio.NewWriter(w)
+
+	for _, match := range matches {
+		fmt.Fprintf(out, "%s\t%s\t%s\n",
+			cast.TimeAt(match.Time), cast.EventAt(match.Index), match.Line)
+	}
+
+	return out.Flush()
+}
+
+func searchAction(c *cli.Context) (err error) {
+	var (
+		pattern = c.Args().Get(0)
+		input   = c.Args().Get(1)
+	)
+
+	re, err := compilePattern(pattern, c.Bool("regexp"), c.Bool("ignore-case"))
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	decoded, err := readCast(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	matches, err := vt.Search(decoded, re)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	err = writeMatches(os.Stdout, matches)
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to write matches"), 1)
+		return
+	}
+
+	if len(matches) == 0 {
+		err = cli.NewExitError(errors.Errorf("no matches found"), 1)
+		return
+	}
+
+	return
+}
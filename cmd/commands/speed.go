@@ -13,12 +13,19 @@ var Speed = cli.Command{
    If no file name is specified as a positional argument, a cast is
    expected to be serverd via stdin.
 
-   If no range is specified (start=0, end=0), the whole event stream
-   is processed.
+   If no range is specified, the whole event stream is processed. When
+   only one end is specified, the other one defaults to the first (or
+   last) event.
+
+   Several ranges can be processed at once with '--range' (instead of
+   '--start' and '--end'), each one optionally with its own factor
+   ('start,end,factor', defaulting to '--factor'). All of them refer to
+   the timestamps of the original cast and must not overlap.
 
    Once the transformation has been performed, the resulting cast is
    either written to a file specified in the '--out' flag or to stdout
    (default).
+` + positionUsage + `
 
 EXAMPLES:
    Make the whole cast ("123.cast") twice as slow:
@@ -34,7 +41,23 @@ EXAMPLES:
      asciinema-edit speed \
         --factor 2 \
         --start 12.231 \
-        --factor 45.333 \
+        --end 45.333 \
+        ./123.cast
+
+   Make the section between 1m and 2m30s twice as fast:
+
+     asciinema-edit speed \
+        --factor 0.5 \
+        --start 1:00 \
+        --end 2:30 \
+        ./123.cast
+
+   Speed up two boring parts, the second one even more:
+
+     asciinema-edit speed \
+        --factor 0.5 \
+        --range 0:10,0:40 \
+        --range 1:00,2:30,0.2 \
         ./123.cast`,
 	ArgsUsage: "[filename]",
 	Action:    speedAction,
@@ -43,34 +66,52 @@ EXAMPLES:
 			Name:  "factor",
 			Usage: "number by which delays are multiplied by",
 		},
-		cli.Float64Flag{
+		cli.StringFlag{
 			Name:  "start",
 			Usage: "initial frame timestamp",
 		},
-		cli.Float64Flag{
+		cli.StringFlag{
 			Name:  "end",
 			Usage: "final frame timestamp",
 		},
+		cli.StringSliceFlag{
+			Name:  "range",
+			Usage: "range of frames to process, as 'start,end[,factor]' (can be repeated)",
+		},
+		snapFlag,
 		cli.StringFlag{
 			Name:  "out",
 			Usage: "file to write the modified contents to",
 		},
+		outputVersionFlag,
 	},
 }
 
 type speedTransformation struct {
-	from   float64
-	to     float64
+	span   cast.Range
+	policy cast.SnapPolicy
 	factor float64
 }
 
 func (t *speedTransformation) Transform(c *cast.Cast) (err error) {
-	if t.from == 0 && t.to == 0 {
-		t.from = c.EventStream[0].Time
-		t.to = c.EventStream[len(c.EventStream)-1].Time
-	}
+	err = cast.SpeedRange(c, t.factor, t.span, t.policy)
+	return
+}
 
-	err = cast.Speed(c, t.factor, t.from, t.to)
+func (t *speedTransformation) TransformStream(src cast.EventSource, dst cast.EventSink) (err error) {
+	err = cast.SpeedStream(src, dst, t.factor, t.span, t.policy)
+	return
+}
+
+// speedRangesTransformation processes several ranges at once, which
+// needs the whole cast (so it has no streaming variant).
+type speedRangesTransformation struct {
+	ranges []cast.FactorRange
+	policy cast.SnapPolicy
+}
+
+func (t *speedRangesTransformation) Transform(c *cast.Cast) (err error) {
+	err = cast.SpeedRanges(c, t.ranges, t.policy)
 	return
 }
 
@@ -78,13 +119,41 @@ func speedAction(c *cli.Context) (err error) {
 	var (
 		input          = c.Args().First()
 		output         = c.String("out")
-		transformation = &speedTransformation{
-			factor: c.Float64("factor"),
-			from:   c.Float64("start"),
-			to:     c.Float64("end"),
-		}
+		first          = cast.EventAt(0)
+		last           = cast.EventAt(-1)
+		factor         = c.Float64("factor")
+		transformation transformer.Transformation
 	)
 
+	ranges, err := parseRangesFlag(c, true, factor)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	if len(ranges) > 0 {
+		multi := &speedRangesTransformation{ranges: ranges}
+
+		multi.policy, err = cast.ParseSnapPolicy(c.String("snap"))
+		transformation = multi
+	} else {
+		single := &speedTransformation{factor: factor}
+
+		single.span, single.policy, err = parseRangeFlags(c, &first, &last)
+		transformation = single
+	}
+
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	outputVersion, err := parseOutputVersionFlag(c)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	t, err := transformer.New(transformation, input, output)
 	if err != nil {
 		err = cli.NewExitError(err, 1)
@@ -92,6 +161,12 @@ func speedAction(c *cli.Context) (err error) {
 	}
 	defer t.Close()
 
+	err = t.SetOutputVersion(outputVersion)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
 	err = t.Transform()
 	if err != nil {
 		err = cli.NewExitError(err, 1)
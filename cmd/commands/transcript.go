@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var Transcript = cli.Command{
+	Name: "transcript",
+	Usage: `Writes the text that a cast shows as plain text.
+
+   The cast is played into a virtual terminal of the size declared in
+   its header (following any resize events), so that escape sequences,
+   carriage-return redraws and output split across events end up as
+   the text the viewer saw.
+
+   The transcript holds every line that scrolled off (or got cleared
+   from) the screen followed by the final screen, with lines that were
+   wrapped at the right margin joined back. With '--timestamps', each
+   line is prefixed with the time (in the cast) at which text first
+   appeared on it, as '[mm:ss]'.
+
+   If no file name is specified as a positional argument, a cast is
+   expected to be served via stdin.
+
+   The result is either written to a file specified in the '--out'
+   flag or to stdout (default).
+
+EXAMPLES:
+   Print the text of a recording:
+
+     asciinema-edit transcript 1234.cast
+
+   Save a transcript with timestamps to be attached to a ticket:
+
+     asciinema-edit transcript --timestamps --out 1234.txt 1234.cast`,
+	ArgsUsage: "[filename]",
+	Action:    transcriptAction,
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "timestamps",
+			Usage: "prefix each line with the time it appeared at",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the transcript to",
+		},
+	},
+}
+
+// writeTranscript writes the lines of a transcript, one per line.
+func writeTranscript(w io.Writer, lines []vt.TranscriptLine, timestamps bool) error {
+	out := bufio.NewWriter(w)
+
+	for _, line := range lines {
+		if timestamps && line.Text != "" {
+			fmt.Fprintf(out, "[%s] ", formatTimestamp(line.Time))
+		}
+
+		fmt.Fprintln(out, line.Text)
+	}
+
+	return out.Flush()
+}
+
+// formatTimestamp formats a timestamp as `mm:ss`.
+func formatTimestamp(t cast.Time) string {
+	seconds := int(t / cast.Second)
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+func transcriptAction(c *cli.Context) (err error) {
+	var (
+		input  = c.Args().First()
+		output = c.String("out")
+	)
+
+	decoded, err := readCast(input)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	lines, err := vt.Transcript(decoded)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+
+	out, err := createOutput(output)
+	if err != nil {
+		err = cli.NewExitError(err, 1)
+		return
+	}
+	defer out.Close()
+
+	err = writeTranscript(out, lines, c.Bool("timestamps"))
+	if err != nil {
+		err = cli.NewExitError(errors.Wrapf(err, "failed to write transcript"), 1)
+		return
+	}
+
+	return
+}
@@ -5,6 +5,7 @@
 package transformer
 
 import (
+	"io"
 	"os"
 
 	"github.com/pkg/errors"
@@ -20,6 +21,18 @@ type Transformation interface {
 	Transform(c *cast.Cast) (err error)
 }
 
+// StreamTransformation describes an operation that is able to
+// mutate a cast while it's being read, one event at a time, without
+// holding the whole cast in memory.
+//
+// Whenever a Transformation also implements StreamTransformation,
+// `Transformer` prefers the streaming variant.
+type StreamTransformation interface {
+	// TransformStream reads events from `src`, writing the
+	// transformed ones to `dst`.
+	TransformStream(src cast.EventSource, dst cast.EventSink) (err error)
+}
+
 // Transformer wraps the agents in a tranformation pipeline.
 // Once created (see `New`), whenever a transformation is meant
 // to be performed (see `Transform`), `Transformer` will read a
@@ -30,12 +43,14 @@ type Transformation interface {
 //
 //	input ==> transformation ==> output
 //
-// Note.: `input` will be consumed until EOF before the transformation
-// is applied.
+// Note.: unless the transformation implements `StreamTransformation`,
+// `input` will be consumed until EOF before the transformation is
+// applied.
 type Transformer struct {
 	input          *os.File
 	output         *os.File
 	transformation Transformation
+	outputVersion  uint8
 }
 
 // New instantiates a new Transformer instance.
@@ -92,11 +107,37 @@ func New(t Transformation, input, output string) (m *Transformer, err error) {
 	return
 }
 
+// SetOutputVersion makes the transformed cast be encoded using the
+// asciicast version `version` (2 or 3) regardless of the version of
+// the input cast.
+//
+// A zero version keeps the version of the input cast.
+func (m *Transformer) SetOutputVersion(version uint8) error {
+	if version != 0 && version != 2 && version != 3 {
+		return errors.Errorf("output version must be either 2 or 3")
+	}
+
+	m.outputVersion = version
+	return nil
+}
+
+// setVersion updates the header of the cast being transformed with the
+// output version, if any.
+func (m *Transformer) setVersion(header *cast.Header) {
+	if m.outputVersion != 0 {
+		header.Version = m.outputVersion
+	}
+}
+
 // Transform performs the central piece of the cast transformation process:
 // 1. decodes a cast from `input`; then
 // 2. applies the transformation in the cast that now lives in memory; then
 // 3. encodes the cast, saving it to `output`.
 func (m *Transformer) Transform() error {
+	if t, ok := m.transformation.(StreamTransformation); ok {
+		return m.transformStream(t)
+	}
+
 	var decodedCast *cast.Cast
 
 	decodedCast, err := cast.Decode(m.input)
@@ -118,6 +159,8 @@ func (m *Transformer) Transform() error {
 			"failed to transform cast")
 	}
 
+	m.setVersion(&decodedCast.Header)
+
 	err = decodedCast.Encode(m.output)
 	if err != nil {
 		return errors.Wrapf(err,
@@ -126,6 +169,68 @@ func (m *Transformer) Transform() error {
 	return nil
 }
 
+// transformStream performs the transformation one event at a time:
+// 1. decodes the cast header from `input` and encodes it to `output`; then
+// 2. lets the transformation pull events from `input` and push the
+// transformed ones to `output`.
+func (m *Transformer) transformStream(t StreamTransformation) error {
+	reader, err := cast.NewReader(m.input)
+	if err != nil {
+		return errors.Wrapf(err,
+			"failed to decode cast from input")
+	}
+
+	err = reader.Header().ValidateHeader()
+	if err != nil {
+		return errors.Wrapf(err,
+			"invalid input cast")
+	}
+
+	header := *reader.Header()
+	m.setVersion(&header)
+
+	writer, err := cast.NewWriter(m.output, &header)
+	if err != nil {
+		return errors.Wrapf(err,
+			"failed to save modified cast")
+	}
+
+	err = t.TransformStream(&orderedSource{src: reader}, writer)
+	if err != nil {
+		return errors.Wrapf(err,
+			"failed to transform cast")
+	}
+
+	return nil
+}
+
+// orderedSource wraps an EventSource making sure that the events are
+// ordered by time (see `cast.ValidateEventStream`).
+type orderedSource struct {
+	src      cast.EventSource
+	lastTime cast.Time
+}
+
+func (s *orderedSource) NextEvent() (*cast.Event, error) {
+	ev, err := s.src.NextEvent()
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+
+		return nil, errors.Wrapf(err,
+			"invalid input cast")
+	}
+
+	if ev.Time < s.lastTime {
+		return nil, errors.Errorf(
+			"invalid input cast: events must be ordered by time")
+	}
+
+	s.lastTime = ev.Time
+	return ev, nil
+}
+
 // Close closes any open resources (input and output).
 func (m *Transformer) Close() (err error) {
 	if m.output != nil && m.output != os.Stdout {
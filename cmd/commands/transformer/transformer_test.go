@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -16,6 +17,28 @@ func (t *DummyTransformation) Transform(c *cast.Cast) error {
 	return nil
 }
 
+type DummyStreamTransformation struct {
+	DummyTransformation
+}
+
+func (t *DummyStreamTransformation) TransformStream(src cast.EventSource, dst cast.EventSink) error {
+	for {
+		ev, err := src.NextEvent()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = dst.WriteEvent(ev)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func TestTransformer(t *testing.T) {
 
 	t.Run("with nil transform", func(t *testing.T) {
@@ -181,6 +204,83 @@ func TestTransformer(t *testing.T) {
 	})
 }
 
+func TestTransformer_Stream(t *testing.T) {
+	var (
+		trans  *Transformer
+		input  string
+		output string
+		err    error
+	)
+
+	setup := func(content string) {
+		input, err = createTempFileWithContent(content)
+		assert.NoError(t, err)
+
+		output, err = createTempFileWithContent("")
+		assert.NoError(t, err)
+
+		trans, err = New(
+			&DummyStreamTransformation{},
+			input,
+			output)
+		assert.NoError(t, err)
+	}
+
+	teardown := func() {
+		trans.Close()
+		os.Remove(input)
+		os.Remove(output)
+	}
+
+	t.Run("with malformed input", func(t *testing.T) {
+		setup("malformed")
+		defer teardown()
+
+		err = trans.Transform()
+		assert.Error(t, err)
+	})
+
+	t.Run("with invalid header", func(t *testing.T) {
+		setup(`{"version": 2, "width": 0, "height": 123}`)
+		defer teardown()
+
+		err = trans.Transform()
+		assert.Error(t, err)
+	})
+
+	t.Run("with malformed event stream", func(t *testing.T) {
+		setup(`{"version": 2, "width": 123, "height": 123}
+[1, "o", "aaa"]
+[3, "o", "ccc"]
+[2, "o", "bbb"]`)
+		defer teardown()
+
+		err = trans.Transform()
+		assert.Error(t, err)
+	})
+
+	t.Run("with well formed event stream", func(t *testing.T) {
+		setup(`{"version": 2, "width": 123, "height": 123}
+[1, "o", "aaa"]
+[2, "o", "bbb"]
+[3, "o", "ccc"]`)
+		defer teardown()
+
+		err = trans.Transform()
+		assert.NoError(t, err)
+
+		trans.Close()
+
+		file, err := os.Open(output)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		decoded, err := cast.Decode(file)
+		assert.NoError(t, err)
+		assert.Len(t, decoded.EventStream, 3)
+	})
+}
+
 func createTempFileWithContent(content string) (res string, err error) {
 	var file *os.File
 
@@ -27,8 +27,22 @@ func main() {
    when it comes to editing a cast that has already been recorded.`
 	app.Commands = []cli.Command{
 		commands.Cut,
+		commands.Extract,
 		commands.Quantize,
+		commands.Pace,
 		commands.Speed,
+		commands.Convert,
+		commands.Redact,
+		commands.Replace,
+		commands.Anonymize,
+		commands.Concat,
+		commands.Insert,
+		commands.Frame,
+		commands.Export,
+		commands.Transcript,
+		commands.Search,
+		commands.Info,
+		commands.Lint,
 		commands.Record,
 		commands.Play,
 	}
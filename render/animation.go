@@ -0,0 +1,77 @@
+package render
+
+import (
+	"math"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// FrameEncoder turns the frames of a recording (see `vt.Frames`) into an
+// animation, one frame at a time.
+type FrameEncoder interface {
+	// WriteFrame adds a frame to the animation. Frames must be written
+	// in chronological order.
+	WriteFrame(s *vt.Snapshot) error
+
+	// Close finishes the animation, writing whatever is still pending.
+	Close() error
+}
+
+// frameEncoderFunc adapts a function into a FrameEncoder that has
+// nothing to do when closed.
+type frameEncoderFunc func(s *vt.Snapshot) error
+
+func (f frameEncoderFunc) WriteFrame(s *vt.Snapshot) error {
+	return f(s)
+}
+
+func (f frameEncoderFunc) Close() error {
+	return nil
+}
+
+// frameLimiter caps the frame rate of an animation: time is split into
+// slots of `1/fps` seconds and only the last frame of each slot is
+// kept, shown from the beginning of the slot.
+type frameLimiter struct {
+	fps     float64
+	emit    func(s *vt.Snapshot) error
+	pending *vt.Snapshot
+	slot    float64
+}
+
+func newFrameLimiter(fps float64, emit func(s *vt.Snapshot) error) *frameLimiter {
+	return &frameLimiter{fps: fps, emit: emit}
+}
+
+func (l *frameLimiter) WriteFrame(s *vt.Snapshot) error {
+	slot := math.Floor(s.Time.Seconds() * l.fps)
+
+	if l.pending != nil && slot != l.slot {
+		err := l.flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	l.pending = s
+	l.slot = slot
+
+	return nil
+}
+
+func (l *frameLimiter) flush() error {
+	if l.pending == nil {
+		return nil
+	}
+
+	frame := *l.pending
+	frame.Time = cast.Seconds(l.slot / l.fps)
+	l.pending = nil
+
+	return l.emit(&frame)
+}
+
+func (l *frameLimiter) Close() error {
+	return l.flush()
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// maxGIFFPS is the highest frame rate that GIF viewers honor: most of
+// them slow down frames shorter than 2 hundredths of a second.
+const maxGIFFPS = 50
+
+// GIFEncoder draws the frames of a recording as an animated GIF that
+// loops forever.
+//
+// The image keeps the size of the first frame, cropping (or padding)
+// the screen whenever the terminal gets resized. Only the part of the
+// screen that changed since the previous frame is stored, and each
+// frame gets a palette of its own (falling back to a fixed palette for
+// frames with more than 256 colors).
+type GIFEncoder struct {
+	w       io.Writer
+	opts    Options
+	limiter *frameLimiter
+
+	anim gif.GIF
+
+	// last is the image of the previous frame and lastTime its
+	// timestamp, in hundredths of a second.
+	last     *image.RGBA
+	lastTime int
+}
+
+// NewGIF creates an encoder that writes a GIF image to `w` once closed.
+//
+// The frame rate is capped at 50 frames per second (or `opts.FPS`, if
+// lower).
+func NewGIF(w io.Writer, opts Options) *GIFEncoder {
+	fps := opts.FPS
+	if fps <= 0 || fps > maxGIFFPS {
+		fps = maxGIFFPS
+	}
+
+	e := &GIFEncoder{w: w, opts: opts}
+	e.limiter = newFrameLimiter(fps, e.addFrame)
+
+	return e
+}
+
+// WriteFrame adds a frame to the animation.
+func (e *GIFEncoder) WriteFrame(s *vt.Snapshot) error {
+	return e.limiter.WriteFrame(s)
+}
+
+// centiseconds converts a timestamp to the unit of GIF delays.
+func centiseconds(t float64) int {
+	return int(math.Round(t * 100))
+}
+
+func (e *GIFEncoder) addFrame(s *vt.Snapshot) error {
+	var (
+		img    = Image(s, e.opts)
+		bounds = img.Bounds()
+		now    = centiseconds(s.Time.Seconds())
+	)
+
+	if e.last != nil {
+		if !e.last.Bounds().Eq(bounds) {
+			// the terminal got resized: keep the size of the first frame.
+			canvas := image.NewRGBA(e.last.Bounds())
+			fillRect(canvas, canvas.Bounds(), e.opts.Theme.Bg)
+			draw.Draw(canvas, canvas.Bounds(), img, image.Point{}, draw.Src)
+			img = canvas
+		}
+
+		bounds = changedBounds(e.last, img)
+		if bounds.Empty() {
+			return nil
+		}
+
+		e.anim.Delay[len(e.anim.Delay)-1] = now - e.lastTime
+	}
+
+	e.anim.Image = append(e.anim.Image, paletted(img, bounds))
+	e.anim.Delay = append(e.anim.Delay, 0)
+	e.anim.Disposal = append(e.anim.Disposal, gif.DisposalNone)
+
+	e.last = img
+	e.lastTime = now
+
+	return nil
+}
+
+// Close writes the GIF image.
+func (e *GIFEncoder) Close() error {
+	err := e.limiter.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(e.anim.Image) == 0 {
+		return errors.Errorf("at least one frame must be supplied")
+	}
+
+	e.anim.Delay[len(e.anim.Delay)-1] = centiseconds(e.opts.LoopDelay)
+	e.anim.Config = image.Config{
+		Width:  e.last.Bounds().Dx(),
+		Height: e.last.Bounds().Dy(),
+	}
+
+	err = gif.EncodeAll(e.w, &e.anim)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode gif")
+	}
+
+	return nil
+}
+
+// changedBounds returns the smallest rectangle that holds every pixel
+// that differs between two images of the same size.
+func changedBounds(prev, next *image.RGBA) image.Rectangle {
+	var (
+		bounds  = next.Bounds()
+		changed image.Rectangle
+	)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		var (
+			from = next.PixOffset(bounds.Min.X, y)
+			to   = next.PixOffset(bounds.Max.X, y)
+		)
+
+		a, b := prev.Pix[from:to], next.Pix[from:to]
+		if string(a) == string(b) {
+			continue
+		}
+
+		minX, maxX := 0, len(a)/4-1
+		for ; minX < maxX && string(a[minX*4:minX*4+4]) == string(b[minX*4:minX*4+4]); minX++ {
+		}
+		for ; maxX > minX && string(a[maxX*4:maxX*4+4]) == string(b[maxX*4:maxX*4+4]); maxX-- {
+		}
+
+		changed = changed.Union(image.Rect(
+			bounds.Min.X+minX, y, bounds.Min.X+maxX+1, y+1))
+	}
+
+	return changed
+}
+
+// paletted converts part of an image to a paletted one, with a palette
+// made of the colors it uses if there are at most 256 of them.
+func paletted(img *image.RGBA, bounds image.Rectangle) *image.Paletted {
+	var (
+		colors  = map[color.RGBA]uint8{}
+		pal     color.Palette
+		overrun bool
+	)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y && !overrun; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if _, ok := colors[c]; ok {
+				continue
+			}
+
+			if len(pal) == 256 {
+				overrun = true
+				break
+			}
+
+			colors[c] = uint8(len(pal))
+			pal = append(pal, c)
+		}
+	}
+
+	if overrun {
+		res := image.NewPaletted(bounds, palette.Plan9)
+		draw.Draw(res, bounds, img, bounds.Min, draw.Src)
+		return res
+	}
+
+	res := image.NewPaletted(bounds, pal)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			res.SetColorIndex(x, y, colors[img.RGBAAt(x, y)])
+		}
+	}
+
+	return res
+}
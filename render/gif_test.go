@@ -0,0 +1,68 @@
+package render_test
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+)
+
+func TestGIFEncoder(t *testing.T) {
+	c := &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 4, Height: 2},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(0.5), Type: "o", Data: "a"},
+			{Time: cast.Seconds(0.505), Type: "o", Data: "b"},
+			{Time: cast.Seconds(1.25), Type: "o", Data: "\r\n"},
+			{Time: cast.Seconds(2), Type: "r", Data: "8x4"},
+			{Time: cast.Seconds(3), Type: "o", Data: "c"},
+		},
+	}
+
+	opts := render.Options{Theme: render.DefaultTheme, Scale: 1, LoopDelay: 2}
+
+	t.Run("Fails without frames", func(t *testing.T) {
+		assert.Error(t, render.NewGIF(new(bytes.Buffer), opts).Close())
+	})
+
+	encode := func(opts render.Options) *gif.GIF {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, encodeAll(render.NewGIF(buf, opts), collectFrames(t, c)))
+
+		res, err := gif.DecodeAll(buf)
+		assert.NoError(t, err)
+
+		return res
+	}
+
+	t.Run("Derives delays from the event times", func(t *testing.T) {
+		res := encode(opts)
+
+		// frames are shown from the beginning of their 1/50s slot, and
+		// the resize doesn't change anything in view.
+		assert.Equal(t, 0, res.LoopCount)
+		assert.Equal(t, []int{50, 74, 176, 200}, res.Delay)
+	})
+
+	t.Run("Stores the changed regions only", func(t *testing.T) {
+		res := encode(opts)
+
+		cw, ch := opts.CellSize()
+		assert.Equal(t, image.Rect(0, 0, 4*cw, 2*ch), res.Image[0].Bounds())
+		assert.Equal(t, 4*cw, res.Config.Width)
+		assert.True(t, res.Image[1].Bounds().In(image.Rect(0, 0, 3*cw, ch)))
+	})
+
+	t.Run("Caps the frame rate", func(t *testing.T) {
+		capped := opts
+		capped.FPS = 1
+
+		res := encode(capped)
+		assert.Equal(t, []int{100, 200, 200}, res.Delay)
+	})
+}
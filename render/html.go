@@ -0,0 +1,391 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// Chapter is a point of a recording that the HTML player lets viewers
+// jump to (see `HTMLInfo`).
+type Chapter struct {
+	Time  cast.Time
+	Label string
+}
+
+// HTMLInfo holds what the HTML player shows besides the frames.
+type HTMLInfo struct {
+	// Title is the title of the page.
+	Title string
+
+	// Duration is the length of the recording.
+	Duration cast.Time
+
+	// Chapters lists the points of the recording to jump to (usually
+	// its markers).
+	Chapters []Chapter
+}
+
+// HTMLEncoder writes the frames of a recording as a self-contained HTML
+// page with a small player (play/pause, seek bar, speed control and
+// chapters) that works offline.
+//
+// Each distinct screen is rendered once, as styled text.
+type HTMLEncoder struct {
+	w    io.Writer
+	opts Options
+	info HTMLInfo
+	next FrameEncoder
+
+	width, height int
+
+	ids     map[string]int
+	screens []string
+	frames  [][2]float64
+}
+
+// NewHTML creates an encoder that writes an HTML page to `w` once closed.
+func NewHTML(w io.Writer, opts Options, info HTMLInfo) *HTMLEncoder {
+	e := &HTMLEncoder{
+		w:    w,
+		opts: opts,
+		info: info,
+		ids:  map[string]int{},
+	}
+
+	e.next = frameEncoderFunc(e.addFrame)
+	if opts.FPS > 0 {
+		e.next = newFrameLimiter(opts.FPS, e.addFrame)
+	}
+
+	return e
+}
+
+// WriteFrame adds a frame to the page.
+func (e *HTMLEncoder) WriteFrame(s *vt.Snapshot) error {
+	return e.next.WriteFrame(s)
+}
+
+func (e *HTMLEncoder) addFrame(s *vt.Snapshot) error {
+	if s.Width > e.width {
+		e.width = s.Width
+	}
+
+	if s.Height > e.height {
+		e.height = s.Height
+	}
+
+	markup := htmlScreen(s, e.opts)
+
+	id, ok := e.ids[markup]
+	if !ok {
+		id = len(e.screens)
+		e.ids[markup] = id
+		e.screens = append(e.screens, markup)
+	}
+
+	e.frames = append(e.frames, [2]float64{s.Time.Seconds(), float64(id)})
+
+	return nil
+}
+
+// Close writes the HTML page.
+func (e *HTMLEncoder) Close() error {
+	err := e.next.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(e.frames) == 0 {
+		return errors.Errorf("at least one frame must be supplied")
+	}
+
+	duration := e.info.Duration.Seconds()
+	if last := e.frames[len(e.frames)-1][0]; last > duration {
+		duration = last
+	}
+
+	chapters := make([][2]interface{}, len(e.info.Chapters))
+	for idx, chapter := range e.info.Chapters {
+		chapters[idx] = [2]interface{}{chapter.Time.Seconds(), chapter.Label}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"duration": duration,
+		"screens":  e.screens,
+		"frames":   e.frames,
+		"chapters": chapters,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode frames")
+	}
+
+	title := e.info.Title
+	if title == "" {
+		title = "asciinema-edit"
+	}
+
+	out := bufio.NewWriter(e.w)
+
+	err = htmlTemplate.Execute(out, map[string]interface{}{
+		"Title":  html.EscapeString(title),
+		"Fg":     Hex(e.opts.Theme.Fg),
+		"Bg":     Hex(e.opts.Theme.Bg),
+		"Cols":   e.width,
+		"Rows":   e.height,
+		"Data":   string(data),
+		"Script": htmlPlayer,
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to write html")
+	}
+
+	return nil
+}
+
+// htmlScreen renders a screen as lines of styled spans.
+func htmlScreen(s *vt.Snapshot, opts Options) string {
+	var buf strings.Builder
+
+	for y, line := range s.Lines {
+		if y > 0 {
+			buf.WriteByte('\n')
+		}
+
+		var (
+			open  bool
+			style string
+		)
+
+		styles := make([]string, len(line.Cells))
+		end := 0
+
+		for x, cell := range line.Cells {
+			fg, bg := opts.cellColors(s, x, y, cell)
+			styles[x] = htmlStyle(fg, bg, cell.Attrs, opts.Theme)
+
+			if styles[x] != "" || strings.TrimSpace(cell.String()) != "" {
+				end = x + 1
+			}
+		}
+
+		// blanks at the end of lines are left out.
+		for x, cell := range line.Cells[:end] {
+			if cell.Width == 0 {
+				continue
+			}
+
+			next := styles[x]
+			if !open || next != style {
+				if open {
+					buf.WriteString("</span>")
+				}
+
+				if next == "" {
+					buf.WriteString("<span>")
+				} else {
+					fmt.Fprintf(&buf, `<span style="%s">`, next)
+				}
+
+				open = true
+				style = next
+			}
+
+			text := cell.String()
+			if cell.Attrs&vt.AttrHidden != 0 {
+				text = strings.Repeat(" ", int(cell.Width))
+			}
+
+			buf.WriteString(html.EscapeString(text))
+		}
+
+		if open {
+			buf.WriteString("</span>")
+		}
+	}
+
+	return buf.String()
+}
+
+// htmlStyle returns the inline style of a cell, leaving out what the
+// screen already provides.
+func htmlStyle(fg, bg color.RGBA, attrs vt.Attr, theme Theme) string {
+	var props []string
+
+	if fg != theme.Fg {
+		props = append(props, "color:"+Hex(fg))
+	}
+
+	if bg != theme.Bg {
+		props = append(props, "background:"+Hex(bg))
+	}
+
+	if attrs&vt.AttrBold != 0 {
+		props = append(props, "font-weight:bold")
+	}
+
+	if attrs&vt.AttrItalic != 0 {
+		props = append(props, "font-style:italic")
+	}
+
+	switch {
+	case attrs&vt.AttrUnderline != 0 && attrs&vt.AttrStrikethrough != 0:
+		props = append(props, "text-decoration:underline line-through")
+	case attrs&vt.AttrUnderline != 0:
+		props = append(props, "text-decoration:underline")
+	case attrs&vt.AttrStrikethrough != 0:
+		props = append(props, "text-decoration:line-through")
+	}
+
+	return strings.Join(props, ";")
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body{margin:0;padding:2em;background:#fafafa;font-family:sans-serif}
+.player{display:inline-block;background:{{.Bg}};color:{{.Fg}};border-radius:4px;overflow:hidden}
+.screen{margin:0;padding:0.5em;font-family:Consolas,Menlo,'DejaVu Sans Mono','Liberation Mono',monospace;font-size:15px;line-height:1.2;white-space:pre;min-width:{{.Cols}}ch;min-height:calc({{.Rows}} * 1.2em)}
+.controls{display:flex;align-items:center;gap:0.5em;padding:0.4em 0.5em;background:rgba(0,0,0,0.35);font-size:13px}
+.controls button,.controls select{background:none;color:inherit;border:1px solid currentColor;border-radius:3px;cursor:pointer;font:inherit}
+.controls input{flex:1}
+.time{font-family:monospace;white-space:nowrap}
+.chapters{margin:0.5em 0 0;padding:0;list-style:none}
+.chapters li{display:inline-block;margin:0 0.5em 0.5em 0}
+.chapters button{cursor:pointer}
+</style>
+</head>
+<body>
+<div class="player" tabindex="0">
+<pre class="screen"></pre>
+<div class="controls">
+<button class="toggle" title="play/pause (space)">&#9654;</button>
+<input class="seek" type="range" min="0" step="0.01" value="0">
+<span class="time"></span>
+<select class="speed" title="speed">
+<option value="0.5">0.5x</option>
+<option value="1" selected>1x</option>
+<option value="1.5">1.5x</option>
+<option value="2">2x</option>
+<option value="4">4x</option>
+</select>
+</div>
+</div>
+<ol class="chapters"></ol>
+<script>
+var cast = {{.Data}};
+{{.Script}}
+</script>
+</body>
+</html>
+`))
+
+// htmlPlayer is the script of the HTML player. It expects the frames in
+// the `cast` variable.
+const htmlPlayer = `(function () {
+  var player = document.querySelector('.player');
+  var screen = player.querySelector('.screen');
+  var toggle = player.querySelector('.toggle');
+  var seek = player.querySelector('.seek');
+  var time = player.querySelector('.time');
+  var speed = player.querySelector('.speed');
+  var chapters = document.querySelector('.chapters');
+
+  var current = 0, playing = false, startedAt = 0, startTime = 0, shown = -1;
+
+  seek.max = cast.duration;
+
+  function format(t) {
+    var m = Math.floor(t / 60), s = Math.floor(t % 60);
+    return m + ':' + (s < 10 ? '0' : '') + s;
+  }
+
+  function frameAt(t) {
+    var lo = 0, hi = cast.frames.length - 1;
+    while (lo < hi) {
+      var mid = (lo + hi + 1) >> 1;
+      if (cast.frames[mid][0] <= t) { lo = mid; } else { hi = mid - 1; }
+    }
+    return lo;
+  }
+
+  function render() {
+    var idx = cast.frames[frameAt(current)][1];
+    if (idx !== shown) {
+      screen.innerHTML = cast.screens[idx];
+      shown = idx;
+    }
+    seek.value = current;
+    time.textContent = format(current) + ' / ' + format(cast.duration);
+    toggle.innerHTML = playing ? '&#10074;&#10074;' : '&#9654;';
+  }
+
+  function tick() {
+    if (!playing) { return; }
+    current = startTime + (performance.now() - startedAt) / 1000 * speed.value;
+    if (current >= cast.duration) {
+      current = cast.duration;
+      playing = false;
+    }
+    render();
+    if (playing) { requestAnimationFrame(tick); }
+  }
+
+  function play() {
+    if (current >= cast.duration) { current = 0; }
+    playing = true;
+    startTime = current;
+    startedAt = performance.now();
+    requestAnimationFrame(tick);
+  }
+
+  function pause() {
+    playing = false;
+    render();
+  }
+
+  function seekTo(t) {
+    current = Math.max(0, Math.min(cast.duration, t));
+    startTime = current;
+    startedAt = performance.now();
+    render();
+  }
+
+  toggle.addEventListener('click', function () { playing ? pause() : play(); });
+  seek.addEventListener('input', function () { seekTo(parseFloat(seek.value)); });
+  speed.addEventListener('change', function () { seekTo(current); });
+  player.addEventListener('keydown', function (e) {
+    if (e.key === ' ') { playing ? pause() : play(); }
+    else if (e.key === 'ArrowLeft') { seekTo(current - 5); }
+    else if (e.key === 'ArrowRight') { seekTo(current + 5); }
+    else { return; }
+    e.preventDefault();
+  });
+
+  cast.chapters.forEach(function (chapter) {
+    var item = document.createElement('li');
+    var button = document.createElement('button');
+    button.textContent = format(chapter[0]) + ' ' + chapter[1];
+    button.addEventListener('click', function () { seekTo(chapter[0]); player.focus(); });
+    item.appendChild(button);
+    chapters.appendChild(item);
+  });
+
+  render();
+})();`
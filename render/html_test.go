@@ -0,0 +1,75 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+)
+
+func TestHTMLEncoder(t *testing.T) {
+	frames := collectFrames(t, &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 6, Height: 2},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "<a&b>"},
+			{Time: cast.Seconds(2), Type: "o", Data: "\x1b[H\x1b[2J"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\x1b[1;41mbold"},
+		},
+	})
+
+	opts := render.Options{Theme: render.DefaultTheme, HideCursor: true}
+	info := render.HTMLInfo{
+		Title:    "</title>demo",
+		Duration: cast.Seconds(5),
+		Chapters: []render.Chapter{{Time: cast.Seconds(2), Label: "</script>clear"}},
+	}
+
+	t.Run("Fails without frames", func(t *testing.T) {
+		assert.Error(t, render.NewHTML(new(bytes.Buffer), opts, info).Close())
+	})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, encodeAll(render.NewHTML(buf, opts, info), frames))
+	doc := buf.String()
+
+	matches := regexp.MustCompile(`var cast = (.*);\n`).FindStringSubmatch(doc)
+	if !assert.Len(t, matches, 2) {
+		return
+	}
+
+	var data struct {
+		Duration float64
+		Screens  []string
+		Frames   [][2]float64
+		Chapters [][2]interface{}
+	}
+
+	t.Run("Embeds the frames as data", func(t *testing.T) {
+		assert.NoError(t, json.Unmarshal([]byte(matches[1]), &data))
+		assert.Equal(t, 5.0, data.Duration)
+		assert.Len(t, data.Screens, 3)
+		assert.Equal(t, [][2]float64{{0, 0}, {1, 1}, {2, 0}, {3, 2}}, data.Frames)
+		assert.Equal(t, [][2]interface{}{{2.0, "</script>clear"}}, data.Chapters)
+	})
+
+	t.Run("Escapes text, applies styles and trims lines", func(t *testing.T) {
+		assert.Equal(t, "<span>&lt;a&amp;b&gt;</span>\n", data.Screens[1])
+		assert.Equal(t, `<span style="background:#dd3c69;font-weight:bold">bold</span>`+"\n", data.Screens[2])
+	})
+
+	t.Run("Keeps embedded text from closing elements", func(t *testing.T) {
+		assert.Contains(t, doc, "<title>&lt;/title&gt;demo</title>")
+		assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("</script>")))
+	})
+
+	t.Run("Holds the player controls", func(t *testing.T) {
+		for _, class := range []string{"toggle", "seek", "speed", "chapters"} {
+			assert.Contains(t, doc, `class="`+class+`"`)
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// Size of a character cell, in font pixels.
+const (
+	cellWidth  = glyphWidth + 1
+	cellHeight = glyphHeight + 3
+
+	// glyphTop is the row of the cell where glyphs start.
+	glyphTop = 1
+
+	underlineRow     = glyphTop + 8
+	strikethroughRow = glyphTop + 4
+)
+
+// Options tweaks how snapshots are drawn.
+type Options struct {
+	// Theme holds the colors to draw with.
+	Theme Theme
+
+	// Scale is the size (in image pixels) of a pixel of the font.
+	// Defaults to 2.
+	Scale int
+
+	// Padding is the size of the border around the screen, in font
+	// pixels.
+	Padding int
+
+	// HideCursor prevents the cursor from being drawn even if it's
+	// visible in the snapshot.
+	HideCursor bool
+
+	// LoopDelay is the number of seconds that animations hold their
+	// last frame for before starting over.
+	LoopDelay float64
+
+	// FPS caps the number of frames per second of animations. Zero
+	// means that every frame is kept.
+	FPS float64
+}
+
+func (o Options) scale() int {
+	if o.Scale < 1 {
+		return 2
+	}
+
+	return o.Scale
+}
+
+// cellColors returns the colors of the cell at `x`, `y`, drawing the
+// cursor (if visible) as a block.
+func (o Options) cellColors(s *vt.Snapshot, x, y int, cell vt.Cell) (fg, bg color.RGBA) {
+	fg, bg = o.Theme.Colors(cell.Style)
+
+	if s.CursorVisible && !o.HideCursor && x == s.CursorX && y == s.CursorY {
+		fg, bg = bg, fg
+	}
+
+	return
+}
+
+// CellSize returns the size (in image pixels) of a character cell.
+func (o Options) CellSize() (width, height int) {
+	return cellWidth * o.scale(), cellHeight * o.scale()
+}
+
+// Image draws a snapshot of the terminal screen.
+func Image(s *vt.Snapshot, opts Options) *image.RGBA {
+	var (
+		scale   = opts.scale()
+		padding = opts.Padding * scale
+		cw, ch  = opts.CellSize()
+		width   = s.Width*cw + 2*padding
+		height  = s.Height*ch + 2*padding
+		img     = image.NewRGBA(image.Rect(0, 0, width, height))
+	)
+
+	fillRect(img, img.Bounds(), opts.Theme.Bg)
+
+	for y, line := range s.Lines {
+		for x, cell := range line.Cells {
+			if cell.Width == 0 {
+				continue
+			}
+
+			fg, bg := opts.cellColors(s, x, y, cell)
+
+			d := &drawer{
+				img:   img,
+				scale: scale,
+				x:     padding + x*cw,
+				y:     padding + y*ch,
+				cells: int(cell.Width),
+				fg:    fg,
+			}
+
+			if bg != opts.Theme.Bg {
+				d.rect(0, 0, cellWidth*d.cells, cellHeight, bg)
+			}
+
+			if cell.Attrs&vt.AttrHidden == 0 {
+				d.cell(cell)
+			}
+		}
+	}
+
+	return img
+}
+
+// EncodePNG draws a snapshot of the terminal screen as a PNG image.
+func EncodePNG(w io.Writer, s *vt.Snapshot, opts Options) error {
+	err := png.Encode(w, Image(s, opts))
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode png")
+	}
+
+	return nil
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	if r.Empty() {
+		return
+	}
+
+	first := img.Pix[img.PixOffset(r.Min.X, r.Min.Y):img.PixOffset(r.Max.X, r.Min.Y)]
+	for i := 0; i < len(first); i += 4 {
+		first[i], first[i+1], first[i+2], first[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	for y := r.Min.Y + 1; y < r.Max.Y; y++ {
+		copy(img.Pix[img.PixOffset(r.Min.X, y):], first)
+	}
+}
+
+// drawer draws a single cell, with coordinates given in font pixels
+// relative to the top left corner of the cell.
+type drawer struct {
+	img   *image.RGBA
+	scale int
+	x, y  int
+	cells int
+	fg    color.RGBA
+}
+
+func (d *drawer) rect(x, y, w, h int, c color.RGBA) {
+	fillRect(d.img, image.Rect(
+		d.x+x*d.scale, d.y+y*d.scale,
+		d.x+(x+w)*d.scale, d.y+(y+h)*d.scale,
+	), c)
+}
+
+func (d *drawer) cell(cell vt.Cell) {
+	switch {
+	case cell.Rune == 0 || cell.Rune == ' ':
+	case d.boxDrawing(cell.Rune), d.block(cell.Rune):
+	default:
+		d.glyph(cell)
+	}
+
+	if cell.Attrs&vt.AttrUnderline != 0 {
+		d.rect(0, underlineRow, cellWidth*d.cells, 1, d.fg)
+	}
+
+	if cell.Attrs&vt.AttrStrikethrough != 0 {
+		d.rect(0, strikethroughRow, cellWidth*d.cells, 1, d.fg)
+	}
+}
+
+// glyph draws a character of the font, or a box for characters that the
+// font lacks.
+func (d *drawer) glyph(cell vt.Cell) {
+	rows, ok := glyph(cell.Rune)
+	if !ok {
+		w := cellWidth*d.cells - 1
+		d.rect(0, glyphTop, w, 1, d.fg)
+		d.rect(0, glyphTop+6, w, 1, d.fg)
+		d.rect(0, glyphTop, 1, 7, d.fg)
+		d.rect(w-1, glyphTop, 1, 7, d.fg)
+		return
+	}
+
+	bold := cell.Attrs&vt.AttrBold != 0
+	italic := cell.Attrs&vt.AttrItalic != 0
+
+	for y, row := range rows {
+		shift := 0
+		if italic && y < 4 {
+			shift = 1
+		}
+
+		for x := 0; x < glyphWidth; x++ {
+			if row&(1<<(glyphWidth-1-x)) == 0 {
+				continue
+			}
+
+			d.rect(x+shift, glyphTop+y, 1, 1, d.fg)
+			if bold {
+				d.rect(x+shift+1, glyphTop+y, 1, 1, d.fg)
+			}
+		}
+	}
+}
+
+// Arms of box drawing characters.
+const (
+	armUp = 1 << iota
+	armDown
+	armLeft
+	armRight
+)
+
+var boxArms = map[rune]int{}
+
+func init() {
+	for arms, runes := range map[int]string{
+		armLeft | armRight:                   "─━═╌╍┄┅┈┉",
+		armUp | armDown:                      "│┃║╎╏┆┇┊┋",
+		armDown | armRight:                   "┌┍┎┏╒╓╔╭",
+		armDown | armLeft:                    "┐┑┒┓╕╖╗╮",
+		armUp | armRight:                     "└┕┖┗╘╙╚╰",
+		armUp | armLeft:                      "┘┙┚┛╛╜╝╯",
+		armUp | armDown | armRight:           "├┝┞┟┠┡┢┣╞╟╠",
+		armUp | armDown | armLeft:            "┤┥┦┧┨┩┪┫╡╢╣",
+		armDown | armLeft | armRight:         "┬┭┮┯┰┱┲┳╤╥╦",
+		armUp | armLeft | armRight:           "┴┵┶┷┸┹┺┻╧╨╩",
+		armUp | armDown | armLeft | armRight: "┼┽┾┿╀╁╂╃╄╅╆╇╈╉╊╋╪╫╬",
+		armLeft:                              "╴╸",
+		armUp:                                "╵╹",
+		armRight:                             "╶╺",
+		armDown:                              "╷╻",
+	} {
+		for _, r := range runes {
+			boxArms[r] = arms
+		}
+	}
+}
+
+// boxDrawing draws the box drawing characters as lines crossing the
+// center of the cell, so that they connect to their neighbours.
+func (d *drawer) boxDrawing(r rune) bool {
+	arms, ok := boxArms[r]
+	if !ok {
+		return false
+	}
+
+	var (
+		cx = cellWidth / 2
+		cy = cellHeight / 2
+	)
+
+	if arms&armUp != 0 {
+		d.rect(cx, 0, 1, cy+1, d.fg)
+	}
+
+	if arms&armDown != 0 {
+		d.rect(cx, cy, 1, cellHeight-cy, d.fg)
+	}
+
+	if arms&armLeft != 0 {
+		d.rect(0, cy, cx+1, 1, d.fg)
+	}
+
+	if arms&armRight != 0 {
+		d.rect(cx, cy, cellWidth-cx, 1, d.fg)
+	}
+
+	return true
+}
+
+// block draws the block elements (full and half blocks, shades).
+func (d *drawer) block(r rune) bool {
+	var (
+		w = cellWidth
+		h = cellHeight
+	)
+
+	switch r {
+	case '█':
+		d.rect(0, 0, w, h, d.fg)
+	case '▀':
+		d.rect(0, 0, w, h/2, d.fg)
+	case '▄':
+		d.rect(0, h/2, w, h-h/2, d.fg)
+	case '▌':
+		d.rect(0, 0, w/2, h, d.fg)
+	case '▐':
+		d.rect(w/2, 0, w-w/2, h, d.fg)
+	case '░', '▒', '▓':
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				if shade(r, x, y) {
+					d.rect(x, y, 1, 1, d.fg)
+				}
+			}
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// shade tells whether a pixel of a shade character (light, medium or
+// dark) is set.
+func shade(r rune, x, y int) bool {
+	switch r {
+	case '░':
+		return x%2 == 0 && y%2 == 0
+	case '▒':
+		return (x+y)%2 == 0
+	default:
+		return x%2 == 0 || y%2 == 0
+	}
+}
@@ -0,0 +1,45 @@
+package render_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/render"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestImage(t *testing.T) {
+	term := vt.New(4, 2)
+	term.WriteString("\x1b[41m \x1b[0m█\x1b[?25l")
+
+	var (
+		snapshot = term.Snapshot()
+		opts     = render.Options{Theme: render.DefaultTheme, Scale: 1, Padding: 2}
+		img      = render.Image(snapshot, opts)
+		cw, ch   = opts.CellSize()
+	)
+
+	t.Run("Sizes the image after the screen", func(t *testing.T) {
+		assert.Equal(t, 4*cw+4, img.Bounds().Dx())
+		assert.Equal(t, 2*ch+4, img.Bounds().Dy())
+	})
+
+	t.Run("Draws backgrounds and glyphs", func(t *testing.T) {
+		assert.Equal(t, render.DefaultTheme.Bg, img.RGBAAt(0, 0))
+		assert.Equal(t, render.DefaultTheme.Palette[1], img.RGBAAt(2, 2))
+		assert.Equal(t, render.DefaultTheme.Fg, img.RGBAAt(2+cw, 2))
+		assert.Equal(t, render.DefaultTheme.Bg, img.RGBAAt(2+2*cw, 2))
+	})
+
+	t.Run("Encodes to png", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, render.EncodePNG(buf, snapshot, opts))
+
+		decoded, err := png.Decode(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, img.Bounds(), decoded.Bounds())
+	})
+}
@@ -0,0 +1,285 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// svgScale is the size (in SVG user units) of a pixel of the font, so
+// that character cells keep the proportions they have in images.
+const svgScale = 1.5
+
+// svgFontSize is the size of the (monospace) font of SVG documents.
+const svgFontSize = 15
+
+// SVGEncoder draws the frames of a recording as a self-contained
+// animated SVG document.
+//
+// Each distinct screen is drawn once; the animation then moves the
+// right screen into view at the time of every frame using CSS
+// keyframes, looping forever.
+type SVGEncoder struct {
+	w    io.Writer
+	opts Options
+	next FrameEncoder
+
+	width, height int
+
+	ids     map[string]int
+	screens []string
+	uses    []int
+	times   []float64
+}
+
+// NewSVG creates an encoder that writes an SVG document to `w` once
+// closed.
+func NewSVG(w io.Writer, opts Options) *SVGEncoder {
+	e := &SVGEncoder{
+		w:    w,
+		opts: opts,
+		ids:  map[string]int{},
+	}
+
+	e.next = frameEncoderFunc(e.addFrame)
+	if opts.FPS > 0 {
+		e.next = newFrameLimiter(opts.FPS, e.addFrame)
+	}
+
+	return e
+}
+
+// WriteFrame adds a frame to the animation.
+func (e *SVGEncoder) WriteFrame(s *vt.Snapshot) error {
+	return e.next.WriteFrame(s)
+}
+
+func (e *SVGEncoder) addFrame(s *vt.Snapshot) error {
+	if s.Width > e.width {
+		e.width = s.Width
+	}
+
+	if s.Height > e.height {
+		e.height = s.Height
+	}
+
+	markup := svgScreen(s, e.opts, cellWidth*svgScale, cellHeight*svgScale)
+
+	id, ok := e.ids[markup]
+	if !ok {
+		id = len(e.screens)
+		e.ids[markup] = id
+		e.screens = append(e.screens, markup)
+	}
+
+	e.uses = append(e.uses, id)
+	e.times = append(e.times, s.Time.Seconds())
+
+	return nil
+}
+
+// Close writes the SVG document.
+func (e *SVGEncoder) Close() error {
+	err := e.next.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(e.uses) == 0 {
+		return errors.Errorf("at least one frame must be supplied")
+	}
+
+	var (
+		out     = bufio.NewWriter(e.w)
+		padding = float64(e.opts.Padding) * svgScale
+		screenW = float64(e.width) * cellWidth * svgScale
+		screenH = float64(e.height) * cellHeight * svgScale
+	)
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s">`+"\n",
+		num(screenW+2*padding), num(screenH+2*padding))
+
+	fmt.Fprintf(out, "<style>\n"+
+		"text{font-family:Consolas,Menlo,'DejaVu Sans Mono','Liberation Mono',monospace;font-size:%dpx;white-space:pre}\n"+
+		".b{font-weight:bold}.i{font-style:italic}.u{text-decoration:underline}.s{text-decoration:line-through}.u.s{text-decoration:underline line-through}\n",
+		svgFontSize)
+	writeKeyframes(out, e.times, screenH, e.opts.LoopDelay)
+	fmt.Fprint(out, "</style>\n")
+
+	fmt.Fprint(out, "<defs>\n")
+	for id, markup := range e.screens {
+		fmt.Fprintf(out, `<g id="s%d">`+"%s</g>\n", id, markup)
+	}
+	fmt.Fprint(out, "</defs>\n")
+
+	fmt.Fprintf(out, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", Hex(e.opts.Theme.Bg))
+	fmt.Fprintf(out, `<svg x="%s" y="%[1]s" width="%s" height="%s">`+"\n",
+		num(padding), num(screenW), num(screenH))
+
+	fmt.Fprint(out, `<g class="screens">`+"\n")
+	for idx, id := range e.uses {
+		fmt.Fprintf(out, `<use xlink:href="#s%d" y="%s"/>`+"\n",
+			id, num(float64(idx)*screenH))
+	}
+	fmt.Fprint(out, "</g>\n</svg>\n</svg>\n")
+
+	err = out.Flush()
+	if err != nil {
+		return errors.Wrapf(err, "failed to write svg")
+	}
+
+	return nil
+}
+
+// writeKeyframes writes the animation that scrolls the stack of frames
+// so that each one is in view from its timestamp on.
+func writeKeyframes(w io.Writer, times []float64, screenH, loopDelay float64) {
+	if len(times) == 1 {
+		return
+	}
+
+	duration := times[len(times)-1] + loopDelay
+	if duration <= 0 {
+		duration = 1
+	}
+
+	fmt.Fprintf(w, ".screens{animation:frames %ss steps(1,end) infinite}\n", num(duration))
+	fmt.Fprint(w, "@keyframes frames{")
+
+	for idx, t := range times {
+		fmt.Fprintf(w, "%s{transform:translateY(%spx)}",
+			percent(t/duration*100), num(-float64(idx)*screenH))
+	}
+
+	fmt.Fprintf(w, "100%%{transform:translateY(%spx)}}\n",
+		num(-float64(len(times)-1)*screenH))
+}
+
+// svgRun is a piece of a line whose cells share the same colors and
+// attributes. Wide characters get a run of their own so that the text
+// that follows them doesn't depend on how wide the font draws them.
+type svgRun struct {
+	x, cells int
+	fg, bg   color.RGBA
+	attrs    vt.Attr
+	text     strings.Builder
+	blank    bool
+	wide     bool
+}
+
+// svgScreen draws a screen as SVG elements: background rectangles
+// followed by text.
+func svgScreen(s *vt.Snapshot, opts Options, cw, ch float64) string {
+	var (
+		rects strings.Builder
+		texts strings.Builder
+	)
+
+	for y, line := range s.Lines {
+		var runs []*svgRun
+
+		for x, cell := range line.Cells {
+			if cell.Width == 0 {
+				continue
+			}
+
+			fg, bg := opts.cellColors(s, x, y, cell)
+			attrs := cell.Attrs & (vt.AttrBold | vt.AttrItalic | vt.AttrUnderline | vt.AttrStrikethrough)
+
+			text := cell.String()
+			if cell.Attrs&vt.AttrHidden != 0 {
+				text = strings.Repeat(" ", int(cell.Width))
+			}
+
+			var last *svgRun
+			if len(runs) > 0 {
+				last = runs[len(runs)-1]
+			}
+
+			if last == nil || last.fg != fg || last.bg != bg || last.attrs != attrs ||
+				last.wide || cell.Width == 2 || last.x+last.cells != x {
+				last = &svgRun{x: x, fg: fg, bg: bg, attrs: attrs, blank: true, wide: cell.Width == 2}
+				runs = append(runs, last)
+			}
+
+			last.cells += int(cell.Width)
+			last.text.WriteString(text)
+			if strings.TrimSpace(text) != "" {
+				last.blank = false
+			}
+		}
+
+		top := float64(y) * ch
+
+		for _, run := range runs {
+			if run.bg != opts.Theme.Bg {
+				fmt.Fprintf(&rects, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`,
+					num(float64(run.x)*cw), num(top), num(float64(run.cells)*cw), num(ch), Hex(run.bg))
+			}
+
+			if run.blank && run.attrs&(vt.AttrUnderline|vt.AttrStrikethrough) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(&texts, `<text x="%s" y="%s" fill="%s"%s>`,
+				num(float64(run.x)*cw), num(top+ch*0.75), Hex(run.fg), svgClass(run.attrs))
+			xml.EscapeText(&texts, []byte(run.text.String()))
+			texts.WriteString("</text>")
+		}
+	}
+
+	return rects.String() + texts.String()
+}
+
+func svgClass(attrs vt.Attr) string {
+	var classes []string
+
+	for _, a := range []struct {
+		attr  vt.Attr
+		class string
+	}{
+		{vt.AttrBold, "b"},
+		{vt.AttrItalic, "i"},
+		{vt.AttrUnderline, "u"},
+		{vt.AttrStrikethrough, "s"},
+	} {
+		if attrs&a.attr != 0 {
+			classes = append(classes, a.class)
+		}
+	}
+
+	if len(classes) == 0 {
+		return ""
+	}
+
+	return ` class="` + strings.Join(classes, " ") + `"`
+}
+
+// num formats a number compactly (at most two decimal places).
+func num(value float64) string {
+	return round(value, 100)
+}
+
+// percent formats a percentage with enough precision to place frames
+// of long recordings at the right time.
+func percent(value float64) string {
+	return round(value, 10000) + "%"
+}
+
+func round(value, precision float64) string {
+	value = math.Round(value*precision) / precision
+	if value == 0 {
+		// avoid negative zeros.
+		value = 0
+	}
+
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
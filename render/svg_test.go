@@ -0,0 +1,85 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func collectFrames(t *testing.T, c *cast.Cast) (frames []*vt.Snapshot) {
+	err := vt.Frames(c, func(s *vt.Snapshot) error {
+		frames = append(frames, s)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	return
+}
+
+func encodeAll(enc render.FrameEncoder, frames []*vt.Snapshot) error {
+	for _, frame := range frames {
+		err := enc.WriteFrame(frame)
+		if err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
+
+func TestSVGEncoder(t *testing.T) {
+	frames := collectFrames(t, &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 6, Height: 2},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "<a&b>"},
+			{Time: cast.Seconds(2), Type: "o", Data: "\x1b[H\x1b[2J"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\x1b[1;41mbold"},
+		},
+	})
+
+	opts := render.Options{Theme: render.DefaultTheme, LoopDelay: 1, HideCursor: true}
+
+	t.Run("Fails without frames", func(t *testing.T) {
+		assert.Error(t, render.NewSVG(new(bytes.Buffer), opts).Close())
+	})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, encodeAll(render.NewSVG(buf, opts), frames))
+	doc := buf.String()
+
+	t.Run("Produces a well-formed document", func(t *testing.T) {
+		decoder := xml.NewDecoder(strings.NewReader(doc))
+		for {
+			_, err := decoder.Token()
+			if err != nil {
+				assert.Equal(t, "EOF", err.Error())
+				break
+			}
+		}
+	})
+
+	t.Run("Draws each distinct screen once", func(t *testing.T) {
+		assert.Len(t, frames, 4)
+		assert.Equal(t, 3, strings.Count(doc, `<g id="s`))
+		assert.Equal(t, 4, strings.Count(doc, `<use `))
+	})
+
+	t.Run("Animates the frames at their timestamps", func(t *testing.T) {
+		assert.Contains(t, doc, "animation:frames 4s steps(1,end) infinite")
+		assert.Contains(t, doc, "25%{transform:translateY(-36px)}")
+		assert.Contains(t, doc, "75%{transform:translateY(-108px)}")
+	})
+
+	t.Run("Escapes text and applies styles", func(t *testing.T) {
+		assert.Contains(t, doc, "&lt;a&amp;b&gt;")
+		assert.Contains(t, doc, `fill="#dd3c69"/>`)
+		assert.Contains(t, doc, `class="b">bold</text>`)
+	})
+}
@@ -0,0 +1,180 @@
+// Package render draws snapshots of a virtual terminal (see package
+// `vt`) as images and documents.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+// Theme holds the colors that cells are drawn with.
+type Theme struct {
+	// Fg is the default foreground color.
+	Fg color.RGBA
+
+	// Bg is the default background color.
+	Bg color.RGBA
+
+	// Palette holds the 16 base colors of the terminal (the 8 normal
+	// ones followed by their bright variants).
+	Palette [16]color.RGBA
+}
+
+// DefaultTheme is the theme used by the asciinema player.
+var DefaultTheme = Theme{
+	Fg: rgb(0xcc, 0xcc, 0xcc),
+	Bg: rgb(0x12, 0x13, 0x14),
+	Palette: [16]color.RGBA{
+		rgb(0x00, 0x00, 0x00), rgb(0xdd, 0x3c, 0x69),
+		rgb(0x4e, 0xbf, 0x22), rgb(0xdd, 0xaf, 0x3c),
+		rgb(0x26, 0xb0, 0xd7), rgb(0xb9, 0x54, 0xe1),
+		rgb(0x54, 0xe1, 0xb9), rgb(0xd9, 0xd9, 0xd9),
+		rgb(0x4d, 0x4d, 0x4d), rgb(0xdd, 0x3c, 0x69),
+		rgb(0x4e, 0xbf, 0x22), rgb(0xdd, 0xaf, 0x3c),
+		rgb(0x26, 0xb0, 0xd7), rgb(0xb9, 0x54, 0xe1),
+		rgb(0x54, 0xe1, 0xb9), rgb(0xff, 0xff, 0xff),
+	},
+}
+
+func rgb(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+// ThemeFromHeader builds a theme out of the theme declared in a cast
+// header, falling back to `DefaultTheme` for anything missing.
+//
+// Palettes of 8 colors get their bright variants from the normal ones.
+func ThemeFromHeader(theme *cast.Theme) (res Theme, err error) {
+	res = DefaultTheme
+
+	if theme == nil {
+		return
+	}
+
+	if theme.Fg != "" {
+		res.Fg, err = ParseColor(theme.Fg)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid theme foreground")
+			return
+		}
+	}
+
+	if theme.Bg != "" {
+		res.Bg, err = ParseColor(theme.Bg)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid theme background")
+			return
+		}
+	}
+
+	if theme.Palette == "" {
+		return
+	}
+
+	colors := strings.Split(theme.Palette, ":")
+	if len(colors) != 8 && len(colors) != 16 {
+		err = errors.Errorf(
+			"theme palette must have 8 or 16 colors, got %d", len(colors))
+		return
+	}
+
+	for idx, input := range colors {
+		res.Palette[idx], err = ParseColor(input)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid theme palette")
+			return
+		}
+	}
+
+	if len(colors) == 8 {
+		copy(res.Palette[8:], res.Palette[:8])
+	}
+
+	return
+}
+
+// ParseColor parses a color in the `#rrggbb` (or `#rgb`) notation.
+func ParseColor(input string) (res color.RGBA, err error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(input), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	value, perr := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || perr != nil {
+		err = errors.Errorf("malformed color '%s'", input)
+		return
+	}
+
+	res = rgb(uint8(value>>16), uint8(value>>8), uint8(value))
+	return
+}
+
+// Hex returns the `#rrggbb` notation of a color.
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Color resolves a terminal color: `def` for the default color, one of
+// the palette for the first 16 indexes, the xterm 6x6x6 color cube and
+// grayscale ramp for the rest and true colors as they are.
+func (t Theme) Color(c vt.Color, def color.RGBA) color.RGBA {
+	if r, g, b, ok := c.RGB(); ok {
+		return rgb(r, g, b)
+	}
+
+	idx, ok := c.Index()
+	if !ok {
+		return def
+	}
+
+	switch {
+	case idx < 16:
+		return t.Palette[idx]
+	case idx < 232:
+		levels := [6]uint8{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+		idx -= 16
+		return rgb(levels[idx/36], levels[idx/6%6], levels[idx%6])
+	default:
+		level := 8 + (idx-232)*10
+		return rgb(level, level, level)
+	}
+}
+
+// Colors returns the foreground and background colors of a cell drawn
+// with `style`, taking into account bold (which brightens the 8 base
+// colors), faint and inverse.
+func (t Theme) Colors(style vt.Style) (fg, bg color.RGBA) {
+	fgColor := style.Fg
+	if idx, ok := fgColor.Index(); ok && idx < 8 && style.Attrs&vt.AttrBold != 0 {
+		fgColor = vt.IndexedColor(idx + 8)
+	}
+
+	fg = t.Color(fgColor, t.Fg)
+	bg = t.Color(style.Bg, t.Bg)
+
+	if style.Attrs&vt.AttrInverse != 0 {
+		fg, bg = bg, fg
+	}
+
+	if style.Attrs&vt.AttrFaint != 0 {
+		fg = blend(fg, bg)
+	}
+
+	return
+}
+
+// blend mixes two colors evenly.
+func blend(a, b color.RGBA) color.RGBA {
+	return rgb(
+		uint8((uint16(a.R)+uint16(b.R))/2),
+		uint8((uint16(a.G)+uint16(b.G))/2),
+		uint8((uint16(a.B)+uint16(b.B))/2),
+	)
+}
@@ -0,0 +1,81 @@
+package render_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/render"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestParseColor(t *testing.T) {
+	res, err := render.ParseColor("#102030")
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{0x10, 0x20, 0x30, 0xff}, res)
+	assert.Equal(t, "#102030", render.Hex(res))
+
+	res, err = render.ParseColor("#abc")
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{0xaa, 0xbb, 0xcc, 0xff}, res)
+
+	for _, input := range []string{"", "#12", "#1234567", "#gggggg"} {
+		_, err = render.ParseColor(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestThemeFromHeader(t *testing.T) {
+	t.Run("Falls back to the default theme", func(t *testing.T) {
+		res, err := render.ThemeFromHeader(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, render.DefaultTheme, res)
+	})
+
+	t.Run("Expands palettes of 8 colors", func(t *testing.T) {
+		res, err := render.ThemeFromHeader(&cast.Theme{
+			Fg:      "#ffffff",
+			Palette: "#000000:#110000:#220000:#330000:#440000:#550000:#660000:#770000",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, color.RGBA{0xff, 0xff, 0xff, 0xff}, res.Fg)
+		assert.Equal(t, render.DefaultTheme.Bg, res.Bg)
+		assert.Equal(t, color.RGBA{0x11, 0, 0, 0xff}, res.Palette[9])
+	})
+
+	t.Run("Fails on malformed themes", func(t *testing.T) {
+		_, err := render.ThemeFromHeader(&cast.Theme{Bg: "blue"})
+		assert.Error(t, err)
+
+		_, err = render.ThemeFromHeader(&cast.Theme{Palette: "#000000:#ffffff"})
+		assert.Error(t, err)
+	})
+}
+
+func TestTheme_Colors(t *testing.T) {
+	theme := render.DefaultTheme
+
+	tests := []struct {
+		description string
+		style       vt.Style
+		fg, bg      color.RGBA
+	}{
+		{"defaults", vt.Style{}, theme.Fg, theme.Bg},
+		{"palette", vt.Style{Fg: vt.IndexedColor(1), Bg: vt.IndexedColor(12)}, theme.Palette[1], theme.Palette[12]},
+		{"bold brightens", vt.Style{Fg: vt.IndexedColor(0), Attrs: vt.AttrBold}, theme.Palette[8], theme.Bg},
+		{"color cube", vt.Style{Fg: vt.IndexedColor(196)}, color.RGBA{0xff, 0, 0, 0xff}, theme.Bg},
+		{"grayscale", vt.Style{Fg: vt.IndexedColor(232)}, color.RGBA{8, 8, 8, 0xff}, theme.Bg},
+		{"true color", vt.Style{Fg: vt.RGBColor(1, 2, 3)}, color.RGBA{1, 2, 3, 0xff}, theme.Bg},
+		{"inverse", vt.Style{Attrs: vt.AttrInverse}, theme.Bg, theme.Fg},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			fg, bg := theme.Colors(test.style)
+			assert.Equal(t, test.fg, fg)
+			assert.Equal(t, test.bg, bg)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ANSI returns the text of the snapshot along with the escape sequences
+// that reproduce its colors and attributes, one line per row.
+//
+// Trailing blanks drawn with the default style are left out.
+func (s *Snapshot) ANSI() string {
+	rows := make([]string, len(s.Lines))
+	for i, line := range s.Lines {
+		rows[i] = line.ANSI()
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// ANSI returns the text of the line along with the escape sequences that
+// reproduce its colors and attributes.
+func (l Line) ANSI() string {
+	var (
+		buf   strings.Builder
+		style Style
+		end   = len(l.Cells)
+	)
+
+	for end > 0 {
+		cell := l.Cells[end-1]
+		if cell.Width != 0 && (cell.Rune != 0 && cell.Rune != ' ' || cell.Style != Style{}) {
+			break
+		}
+
+		end--
+	}
+
+	for _, cell := range l.Cells[:end] {
+		if cell.Width == 0 {
+			continue
+		}
+
+		if cell.Style != style {
+			buf.WriteString(SGR(cell.Style))
+			style = cell.Style
+		}
+
+		buf.WriteString(cell.String())
+	}
+
+	if (style != Style{}) {
+		buf.WriteString("\x1b[0m")
+	}
+
+	return buf.String()
+}
+
+var attrCodes = []struct {
+	attr Attr
+	code string
+}{
+	{AttrBold, "1"},
+	{AttrFaint, "2"},
+	{AttrItalic, "3"},
+	{AttrUnderline, "4"},
+	{AttrBlink, "5"},
+	{AttrInverse, "7"},
+	{AttrHidden, "8"},
+	{AttrStrikethrough, "9"},
+}
+
+// SGR returns the control sequence that resets the pen and then selects
+// the colors and attributes of `style`.
+func SGR(style Style) string {
+	params := []string{"0"}
+
+	for _, a := range attrCodes {
+		if style.Attrs&a.attr != 0 {
+			params = append(params, a.code)
+		}
+	}
+
+	params = appendColor(params, style.Fg, 30, 90, "38")
+	params = appendColor(params, style.Bg, 40, 100, "48")
+
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+func appendColor(params []string, c Color, base, bright int, extended string) []string {
+	if idx, ok := c.Index(); ok {
+		switch {
+		case idx < 8:
+			return append(params, strconv.Itoa(base+int(idx)))
+		case idx < 16:
+			return append(params, strconv.Itoa(bright+int(idx)-8))
+		default:
+			return append(params, extended, "5", strconv.Itoa(int(idx)))
+		}
+	}
+
+	if r, g, b, ok := c.RGB(); ok {
+		return append(params, extended, "2",
+			strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b)))
+	}
+
+	return params
+}
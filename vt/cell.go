@@ -0,0 +1,174 @@
+package vt
+
+import (
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// Color represents the color of a cell's foreground or background.
+//
+// The zero value is the default color of the terminal (see `Theme`).
+type Color uint32
+
+const (
+	// DefaultColor is the terminal's default foreground (or background).
+	DefaultColor Color = 0
+
+	colorIndexed = 1 << 24
+	colorRGB     = 2 << 24
+	colorKind    = 0xff << 24
+)
+
+// IndexedColor creates a color out of the 256-color palette.
+func IndexedColor(idx uint8) Color {
+	return Color(colorIndexed | uint32(idx))
+}
+
+// RGBColor creates a true color.
+func RGBColor(r, g, b uint8) Color {
+	return Color(colorRGB | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
+}
+
+// IsDefault tells whether the color is the terminal's default one.
+func (c Color) IsDefault() bool {
+	return c == DefaultColor
+}
+
+// Index returns the palette index of an indexed color.
+func (c Color) Index() (idx uint8, ok bool) {
+	return uint8(c), c&colorKind == colorIndexed
+}
+
+// RGB returns the components of a true color.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), c&colorKind == colorRGB
+}
+
+// Attr is a set of text attributes (bold, underline, ...).
+type Attr uint16
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrFaint
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrInverse
+	AttrHidden
+	AttrStrikethrough
+)
+
+// Style groups the colors and attributes a cell is drawn with.
+type Style struct {
+	Fg    Color
+	Bg    Color
+	Attrs Attr
+}
+
+// Cell is a single character cell of the screen.
+type Cell struct {
+	// Rune is the character displayed in the cell; zero for a blank
+	// cell.
+	Rune rune
+
+	// Combining holds any combining characters (accents, variation
+	// selectors, ...) that follow `Rune`.
+	Combining string
+
+	// Width is the number of columns taken by the character: 1 for
+	// regular characters, 2 for the leading cell of a wide character
+	// and 0 for the cell that a wide character spills over to.
+	Width uint8
+
+	Style
+}
+
+// String returns the text of the cell (a space for blank cells and
+// nothing for the trailing half of wide characters).
+func (c Cell) String() string {
+	switch {
+	case c.Width == 0:
+		return ""
+	case c.Rune == 0:
+		return " "
+	default:
+		return string(c.Rune) + c.Combining
+	}
+}
+
+// blankCell returns an empty cell drawn with the background of `style`.
+func blankCell(style Style) Cell {
+	return Cell{
+		Width: 1,
+		Style: Style{Bg: style.Bg},
+	}
+}
+
+// Line is a row of the screen.
+type Line struct {
+	Cells []Cell
+
+	// Wrapped indicates that the text of the line continues on the next
+	// one because it reached the right margin (soft wrap).
+	Wrapped bool
+
+	// Time is the timestamp of the event that first printed text on the
+	// line (see `Terminal.Apply`).
+	Time cast.Time
+
+	// printed tells whether text was ever printed on the line.
+	printed bool
+}
+
+func newLine(width int, style Style) Line {
+	line := Line{Cells: make([]Cell, width)}
+	line.clear(0, width, style)
+
+	return line
+}
+
+// clear blanks the cells from `from` up to (not including) `to`.
+func (l *Line) clear(from, to int, style Style) {
+	for i := from; i < to && i < len(l.Cells); i++ {
+		l.Cells[i] = blankCell(style)
+	}
+}
+
+// resize pads or truncates the line to `width` cells.
+func (l *Line) resize(width int) {
+	switch {
+	case width < len(l.Cells):
+		l.Cells = l.Cells[:width]
+		if width > 0 && l.Cells[width-1].Width == 2 {
+			l.Cells[width-1] = blankCell(l.Cells[width-1].Style)
+		}
+	case width > len(l.Cells):
+		for len(l.Cells) < width {
+			l.Cells = append(l.Cells, blankCell(Style{}))
+		}
+	}
+}
+
+// clone returns a deep copy of the line.
+func (l Line) clone() Line {
+	cells := make([]Cell, len(l.Cells))
+	copy(cells, l.Cells)
+
+	return Line{Cells: cells, Wrapped: l.Wrapped, Time: l.Time, printed: l.printed}
+}
+
+// String returns the text of the line without trailing blanks.
+func (l Line) String() string {
+	var (
+		buf  []byte
+		last int
+	)
+
+	for _, cell := range l.Cells {
+		buf = append(buf, cell.String()...)
+		if cell.Rune != 0 && cell.Rune != ' ' {
+			last = len(buf)
+		}
+	}
+
+	return string(buf[:last])
+}
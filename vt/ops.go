@@ -0,0 +1,685 @@
+package vt
+
+import (
+	"strings"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// decGraphics maps the characters of the DEC special graphics
+// character set (line drawing) to their Unicode equivalents.
+var decGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'b': '␉', 'c': '␌', 'd': '␍', 'e': '␊', 'f': '°',
+	'g': '±', 'h': '␤', 'i': '␋', 'j': '┘', 'k': '┐', 'l': '┌', 'm': '└',
+	'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽', 't': '├',
+	'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥', '{': 'π',
+	'|': '≠', '}': '£', '~': '·',
+}
+
+func (t *Terminal) line(y int) *Line {
+	return &t.screen.lines[y]
+}
+
+// print puts a printable character at the cursor position, advancing
+// the cursor.
+func (t *Terminal) print(r rune) {
+	c := &t.cursor
+
+	if c.charsets[c.gl] == charsetDECGraphics {
+		if mapped, ok := decGraphics[r]; ok {
+			r = mapped
+		}
+	}
+
+	width := cast.RuneWidth(r)
+	if width == 0 {
+		t.combine(r)
+		return
+	}
+
+	if c.wrapPending && t.autowrap {
+		t.wrapLine()
+	}
+
+	if width == 2 && c.x == t.width-1 {
+		if !t.autowrap || t.width < 2 {
+			return
+		}
+
+		t.line(c.y).clear(c.x, c.x+1, c.style)
+		t.wrapLine()
+	}
+
+	line := t.line(c.y)
+	if !line.printed {
+		line.Time = t.now
+		line.printed = true
+	}
+
+	if t.insertMode {
+		t.insertBlanks(width)
+	}
+
+	t.clearWide(line, c.x)
+	if width == 2 {
+		t.clearWide(line, c.x+1)
+	}
+
+	line.Cells[c.x] = Cell{Rune: r, Width: uint8(width), Style: c.style}
+	if width == 2 {
+		line.Cells[c.x+1] = Cell{Width: 0, Style: c.style}
+	}
+
+	t.lastRune = r
+
+	if c.x+width >= t.width {
+		c.x = t.width - 1
+		c.wrapPending = true
+		return
+	}
+
+	c.x += width
+	c.wrapPending = false
+}
+
+// combine attaches a zero-width character to the last printed cell.
+func (t *Terminal) combine(r rune) {
+	var (
+		c    = &t.cursor
+		x    = c.x
+		line = t.line(c.y)
+	)
+
+	if !c.wrapPending {
+		x--
+	}
+
+	if x > 0 && line.Cells[x].Width == 0 {
+		x--
+	}
+
+	if x < 0 || line.Cells[x].Rune == 0 {
+		return
+	}
+
+	if len(line.Cells[x].Combining) < 16 {
+		line.Cells[x].Combining += string(r)
+	}
+}
+
+// clearWide makes sure that overwriting the cell at `x` doesn't leave
+// half of a wide character behind.
+func (t *Terminal) clearWide(line *Line, x int) {
+	if x < 0 || x >= len(line.Cells) {
+		return
+	}
+
+	cell := line.Cells[x]
+	switch {
+	case cell.Width == 2 && x+1 < len(line.Cells):
+		line.Cells[x+1] = blankCell(cell.Style)
+	case cell.Width == 0 && x > 0:
+		line.Cells[x-1] = blankCell(line.Cells[x-1].Style)
+	}
+}
+
+// wrapLine moves the cursor to the beginning of the next line, marking
+// the current one as wrapped.
+func (t *Terminal) wrapLine() {
+	t.line(t.cursor.y).Wrapped = true
+	t.cursor.x = 0
+	t.cursor.wrapPending = false
+	t.index()
+}
+
+// execute performs a C0 control function.
+func (t *Terminal) execute(r rune) {
+	c := &t.cursor
+
+	switch r {
+	case 0x1b:
+		t.parser.reset()
+		t.parser.state = stateEscape
+	case '\b':
+		if c.x > 0 {
+			if c.wrapPending {
+				c.wrapPending = false
+			} else {
+				c.x--
+			}
+		}
+	case '\t':
+		t.tab(1)
+	case '\n', '\v', '\f':
+		t.index()
+		if t.newlineMode {
+			c.x = 0
+		}
+	case '\r':
+		c.x = 0
+		c.wrapPending = false
+	case 0x0e:
+		c.gl = 1
+	case 0x0f:
+		c.gl = 0
+	}
+}
+
+// tab moves the cursor `n` tab stops forward (backwards if negative).
+func (t *Terminal) tab(n int) {
+	c := &t.cursor
+	c.wrapPending = false
+
+	for ; n > 0 && c.x < t.width-1; n-- {
+		c.x++
+		for c.x < t.width-1 && !t.tabs[c.x] {
+			c.x++
+		}
+	}
+
+	for ; n < 0 && c.x > 0; n++ {
+		c.x--
+		for c.x > 0 && !t.tabs[c.x] {
+			c.x--
+		}
+	}
+}
+
+// index moves the cursor down, scrolling if it's at the bottom of the
+// scrolling region (IND).
+func (t *Terminal) index() {
+	c := &t.cursor
+	c.wrapPending = false
+
+	switch {
+	case c.y == t.scrollBottom:
+		t.scrollUp(t.scrollTop, t.scrollBottom, 1)
+	case c.y < t.height-1:
+		c.y++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling if it's at the top of the
+// scrolling region (RI).
+func (t *Terminal) reverseIndex() {
+	c := &t.cursor
+	c.wrapPending = false
+
+	switch {
+	case c.y == t.scrollTop:
+		t.scrollDown(t.scrollTop, t.scrollBottom, 1)
+	case c.y > 0:
+		c.y--
+	}
+}
+
+// scrollUp scrolls the lines from `top` to `bottom` (both included) up
+// by `n` lines, filling the bottom with blank lines.
+//
+// Lines scrolled off the top of the primary screen are kept in the
+// scrollback buffer.
+func (t *Terminal) scrollUp(top, bottom, n int) {
+	if top == 0 && t.screen == t.primary {
+		for _, line := range t.screen.lines[:clamp(n, 0, bottom+1)] {
+			t.pushScrollback(line)
+		}
+	}
+
+	t.deleteLines(top, bottom, n)
+}
+
+// deleteLines removes `n` lines starting at `top`, shifting the lines up
+// to `bottom` (included) up and filling the bottom with blank lines.
+func (t *Terminal) deleteLines(top, bottom, n int) {
+	n = clamp(n, 0, bottom-top+1)
+	lines := t.screen.lines
+
+	for i := 0; i < n; i++ {
+		copy(lines[top:bottom], lines[top+1:bottom+1])
+		lines[bottom] = newLine(t.width, t.cursor.style)
+	}
+}
+
+// scrollDown scrolls the lines from `top` to `bottom` (both included)
+// down by `n` lines, filling the top with blank lines.
+func (t *Terminal) scrollDown(top, bottom, n int) {
+	n = clamp(n, 0, bottom-top+1)
+	lines := t.screen.lines
+
+	for i := 0; i < n; i++ {
+		copy(lines[top+1:bottom+1], lines[top:bottom])
+		lines[top] = newLine(t.width, t.cursor.style)
+	}
+}
+
+// insertBlanks inserts `n` blank cells at the cursor, shifting the rest
+// of the line to the right (ICH).
+func (t *Terminal) insertBlanks(n int) {
+	var (
+		c    = &t.cursor
+		line = t.line(c.y)
+	)
+
+	n = clamp(n, 0, t.width-c.x)
+	t.clearWide(line, c.x)
+
+	copy(line.Cells[c.x+n:], line.Cells[c.x:t.width-n])
+	line.clear(c.x, c.x+n, c.style)
+	t.fixWideEdge(line)
+}
+
+// deleteChars deletes `n` cells at the cursor, shifting the rest of the
+// line to the left (DCH).
+func (t *Terminal) deleteChars(n int) {
+	var (
+		c    = &t.cursor
+		line = t.line(c.y)
+	)
+
+	n = clamp(n, 0, t.width-c.x)
+	t.clearWide(line, c.x)
+	t.clearWide(line, c.x+n-1)
+
+	copy(line.Cells[c.x:], line.Cells[c.x+n:])
+	line.clear(t.width-n, t.width, c.style)
+	t.fixWideEdge(line)
+}
+
+// fixWideEdge clears a wide character cut in half by the right margin.
+func (t *Terminal) fixWideEdge(line *Line) {
+	last := len(line.Cells) - 1
+	if last >= 0 && line.Cells[last].Width == 2 {
+		line.Cells[last] = blankCell(line.Cells[last].Style)
+	}
+
+	if len(line.Cells) > 0 && line.Cells[0].Width == 0 {
+		line.Cells[0] = blankCell(line.Cells[0].Style)
+	}
+}
+
+// eraseInLine erases part of the cursor line (EL).
+func (t *Terminal) eraseInLine(mode int) {
+	var (
+		c    = &t.cursor
+		line = t.line(c.y)
+	)
+
+	switch mode {
+	case 0:
+		t.clearWide(line, c.x)
+		line.clear(c.x, t.width, c.style)
+		line.Wrapped = false
+	case 1:
+		t.clearWide(line, c.x)
+		line.clear(0, c.x+1, c.style)
+	case 2:
+		line.clear(0, t.width, c.style)
+		line.Wrapped = false
+	}
+}
+
+// eraseInDisplay erases part of the screen (ED).
+func (t *Terminal) eraseInDisplay(mode int) {
+	c := &t.cursor
+
+	switch mode {
+	case 0:
+		t.eraseInLine(0)
+		for y := c.y + 1; y < t.height; y++ {
+			t.screen.lines[y] = newLine(t.width, c.style)
+		}
+	case 1:
+		t.eraseInLine(1)
+		for y := 0; y < c.y; y++ {
+			t.screen.lines[y] = newLine(t.width, c.style)
+		}
+	case 2:
+		if t.screen == t.primary {
+			t.saveScreen()
+		}
+
+		for y := 0; y < t.height; y++ {
+			t.screen.lines[y] = newLine(t.width, c.style)
+		}
+	case 3:
+		if t.screen == t.primary && !t.keepHistory {
+			t.scrollback = nil
+		}
+	}
+}
+
+// moveTo moves the cursor to an absolute position, taking origin mode
+// into account.
+func (t *Terminal) moveTo(x, y int) {
+	c := &t.cursor
+	c.wrapPending = false
+
+	minY, maxY := 0, t.height-1
+	if c.originMode {
+		y += t.scrollTop
+		minY, maxY = t.scrollTop, t.scrollBottom
+	}
+
+	c.x = clamp(x, 0, t.width-1)
+	c.y = clamp(y, minY, maxY)
+}
+
+// moveRelative moves the cursor relatively to its position, stopping at
+// the margins (and at the scrolling region if the cursor is inside it).
+func (t *Terminal) moveRelative(dx, dy int) {
+	c := &t.cursor
+	c.wrapPending = false
+
+	minY, maxY := 0, t.height-1
+	if c.y >= t.scrollTop && c.y <= t.scrollBottom {
+		minY, maxY = t.scrollTop, t.scrollBottom
+	}
+
+	c.x = clamp(c.x+dx, 0, t.width-1)
+	c.y = clamp(c.y+dy, minY, maxY)
+}
+
+// saveCursor saves the cursor state into the active screen (DECSC).
+func (t *Terminal) saveCursor() {
+	t.screen.saved = t.cursor
+	t.screen.hasSaved = true
+}
+
+// restoreCursor restores the cursor state saved in the active screen
+// (DECRC).
+func (t *Terminal) restoreCursor() {
+	if !t.screen.hasSaved {
+		t.cursor = cursor{}
+		return
+	}
+
+	t.cursor = t.screen.saved
+	t.cursor.x = clamp(t.cursor.x, 0, t.width-1)
+	t.cursor.y = clamp(t.cursor.y, 0, t.height-1)
+}
+
+// switchScreen switches between the primary and alternate screens,
+// optionally clearing the alternate screen.
+func (t *Terminal) switchScreen(alternate, clear bool) {
+	target := t.primary
+	if alternate {
+		target = t.alternate
+	}
+
+	if target == t.screen {
+		return
+	}
+
+	t.screen = target
+
+	if alternate && clear {
+		for y := range t.alternate.lines {
+			t.alternate.lines[y] = newLine(t.width, Style{})
+		}
+	}
+}
+
+// escDispatch performs the function of an escape sequence.
+func (t *Terminal) escDispatch(final byte) {
+	p := &t.parser
+
+	if len(p.intermediates) > 0 {
+		switch p.intermediates[0] {
+		case '(', ')':
+			set := charsetASCII
+			if final == '0' {
+				set = charsetDECGraphics
+			}
+
+			t.cursor.charsets[p.intermediates[0]-'('] = set
+		case '#':
+			if final == '8' {
+				t.screenAlignment()
+			}
+		}
+
+		return
+	}
+
+	switch final {
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.index()
+	case 'E':
+		t.index()
+		t.cursor.x = 0
+	case 'H':
+		t.tabs[t.cursor.x] = true
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.saveScreen()
+		t.reset()
+	}
+}
+
+// screenAlignment fills the screen with `E` (DECALN).
+func (t *Terminal) screenAlignment() {
+	for y := range t.screen.lines {
+		line := t.line(y)
+		for x := range line.Cells {
+			line.Cells[x] = Cell{Rune: 'E', Width: 1}
+		}
+	}
+
+	t.scrollTop = 0
+	t.scrollBottom = t.height - 1
+	t.moveTo(0, 0)
+}
+
+// csiDispatch performs the function of a control sequence.
+func (t *Terminal) csiDispatch(final byte) {
+	var (
+		p = &t.parser
+		c = &t.cursor
+	)
+
+	if p.private == '?' {
+		switch final {
+		case 'h', 'l':
+			for i := range p.params {
+				t.setPrivateMode(p.rawParam(i, 0), final == 'h')
+			}
+		case 'J':
+			t.eraseInDisplay(p.rawParam(0, 0))
+		case 'K':
+			t.eraseInLine(p.rawParam(0, 0))
+		}
+
+		return
+	}
+
+	if p.private != 0 {
+		return
+	}
+
+	if len(p.intermediates) > 0 {
+		if p.intermediates[0] == '!' && final == 'p' {
+			t.softReset()
+		}
+
+		return
+	}
+
+	switch final {
+	case '@':
+		t.insertBlanks(p.param(0, 1))
+	case 'A':
+		t.moveRelative(0, -p.param(0, 1))
+	case 'B', 'e':
+		t.moveRelative(0, p.param(0, 1))
+	case 'C', 'a':
+		t.moveRelative(p.param(0, 1), 0)
+	case 'D':
+		t.moveRelative(-p.param(0, 1), 0)
+	case 'E':
+		t.moveRelative(0, p.param(0, 1))
+		c.x = 0
+	case 'F':
+		t.moveRelative(0, -p.param(0, 1))
+		c.x = 0
+	case 'G', '`':
+		c.wrapPending = false
+		c.x = clamp(p.param(0, 1)-1, 0, t.width-1)
+	case 'H', 'f':
+		t.moveTo(p.param(1, 1)-1, p.param(0, 1)-1)
+	case 'I':
+		t.tab(p.param(0, 1))
+	case 'Z':
+		t.tab(-p.param(0, 1))
+	case 'J':
+		t.eraseInDisplay(p.rawParam(0, 0))
+	case 'K':
+		t.eraseInLine(p.rawParam(0, 0))
+	case 'L':
+		if c.y >= t.scrollTop && c.y <= t.scrollBottom {
+			t.scrollDown(c.y, t.scrollBottom, p.param(0, 1))
+			c.x = 0
+			c.wrapPending = false
+		}
+	case 'M':
+		if c.y >= t.scrollTop && c.y <= t.scrollBottom {
+			t.deleteLines(c.y, t.scrollBottom, p.param(0, 1))
+			c.x = 0
+			c.wrapPending = false
+		}
+	case 'P':
+		t.deleteChars(p.param(0, 1))
+	case 'S':
+		t.scrollUp(t.scrollTop, t.scrollBottom, p.param(0, 1))
+	case 'T':
+		t.scrollDown(t.scrollTop, t.scrollBottom, p.param(0, 1))
+	case 'X':
+		n := clamp(p.param(0, 1), 0, t.width-c.x)
+		line := t.line(c.y)
+		t.clearWide(line, c.x)
+		t.clearWide(line, c.x+n-1)
+		line.clear(c.x, c.x+n, c.style)
+	case 'b':
+		if t.lastRune != 0 {
+			for n := clamp(p.param(0, 1), 0, 65535); n > 0; n-- {
+				t.print(t.lastRune)
+			}
+		}
+	case 'd':
+		c.wrapPending = false
+		y := p.param(0, 1) - 1
+		if c.originMode {
+			y += t.scrollTop
+		}
+		c.y = clamp(y, 0, t.height-1)
+	case 'g':
+		switch p.rawParam(0, 0) {
+		case 0:
+			t.tabs[c.x] = false
+		case 3:
+			t.tabs = make([]bool, t.width)
+		}
+	case 'h', 'l':
+		for i := range p.params {
+			switch p.rawParam(i, 0) {
+			case 4:
+				t.insertMode = final == 'h'
+			case 20:
+				t.newlineMode = final == 'h'
+			}
+		}
+	case 'm':
+		t.sgr()
+	case 'r':
+		top := p.param(0, 1) - 1
+		bottom := p.param(1, t.height) - 1
+		if bottom >= t.height {
+			bottom = t.height - 1
+		}
+
+		if top < bottom {
+			t.scrollTop = top
+			t.scrollBottom = bottom
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	}
+}
+
+// setPrivateMode sets (or resets) a DEC private mode (DECSET/DECRST).
+func (t *Terminal) setPrivateMode(mode int, set bool) {
+	switch mode {
+	case 6:
+		t.cursor.originMode = set
+		t.moveTo(0, 0)
+	case 7:
+		t.autowrap = set
+		if !set {
+			t.cursor.wrapPending = false
+		}
+	case 25:
+		t.cursorVisible = set
+	case 47:
+		t.switchScreen(set, false)
+	case 1047:
+		if !set && t.screen == t.alternate {
+			for y := range t.alternate.lines {
+				t.alternate.lines[y] = newLine(t.width, Style{})
+			}
+		}
+
+		t.switchScreen(set, false)
+	case 1048:
+		if set {
+			t.saveCursor()
+		} else {
+			t.restoreCursor()
+		}
+	case 1049:
+		if set {
+			t.primary.saved = t.cursor
+			t.primary.hasSaved = true
+			t.switchScreen(true, true)
+		} else {
+			t.switchScreen(false, false)
+			t.restoreCursor()
+		}
+	}
+}
+
+// softReset resets modes, the scrolling region and the pen (DECSTR).
+func (t *Terminal) softReset() {
+	t.cursorVisible = true
+	t.autowrap = true
+	t.insertMode = false
+	t.scrollTop = 0
+	t.scrollBottom = t.height - 1
+	t.cursor.style = Style{}
+	t.cursor.originMode = false
+	t.cursor.charsets = [2]charset{}
+	t.cursor.gl = 0
+	t.screen.hasSaved = false
+}
+
+// oscDispatch performs an operating system command. Only window titles
+// (OSC 0 and 2) are kept track of.
+func (t *Terminal) oscDispatch() {
+	payload := string(t.parser.osc)
+
+	idx := strings.IndexByte(payload, ';')
+	if idx == -1 {
+		return
+	}
+
+	switch payload[:idx] {
+	case "0", "2":
+		t.title = payload[idx+1:]
+	}
+}
@@ -0,0 +1,262 @@
+package vt
+
+// parserState is the state of the escape sequence parser, loosely
+// following the DEC ANSI parser described at [1].
+//
+// [1]: https://vt100.net/emu/dec_ansi_parser
+type parserState uint8
+
+const (
+	stateGround parserState = iota
+	stateEscape
+	stateEscapeIntermediate
+	stateCSI
+	stateCSIIgnore
+	stateOSC
+	stateString
+)
+
+// maxParams bounds the number of parameters of a control sequence.
+const maxParams = 32
+
+// parser accumulates the pieces of an escape sequence until it can be
+// dispatched.
+type parser struct {
+	state parserState
+
+	// private holds the private marker of a control sequence (`?`, `>`,
+	// `<` or `=`), zero if none.
+	private byte
+
+	// intermediates holds the intermediate bytes of the sequence.
+	intermediates []byte
+
+	// params holds the numeric parameters of a control sequence, each
+	// with its sub-parameters (separated by colons). Missing values are
+	// represented by -1.
+	params [][]int
+
+	// osc holds the payload of an operating system command.
+	osc []rune
+
+	// escInString tells whether an ESC was seen inside a string
+	// (possibly the start of the string terminator).
+	escInString bool
+}
+
+func (p *parser) reset() {
+	p.private = 0
+	p.intermediates = p.intermediates[:0]
+	p.params = p.params[:0]
+	p.osc = p.osc[:0]
+	p.escInString = false
+}
+
+// param returns the `idx`-th parameter, `def` if it's missing or zero.
+func (p *parser) param(idx, def int) int {
+	if idx >= len(p.params) || p.params[idx][0] <= 0 {
+		return def
+	}
+
+	return p.params[idx][0]
+}
+
+// rawParam returns the `idx`-th parameter, `def` if it's missing.
+func (p *parser) rawParam(idx, def int) int {
+	if idx >= len(p.params) || p.params[idx][0] < 0 {
+		return def
+	}
+
+	return p.params[idx][0]
+}
+
+// addDigit adds a digit to the parameter being parsed.
+func (p *parser) addDigit(r rune) {
+	if len(p.params) == 0 {
+		p.params = append(p.params, []int{-1})
+	}
+
+	var (
+		param = p.params[len(p.params)-1]
+		value = &param[len(param)-1]
+	)
+
+	if *value < 0 {
+		*value = 0
+	}
+
+	if *value < 1<<20 {
+		*value = *value*10 + int(r-'0')
+	}
+}
+
+// nextParam starts a new parameter (`;`) or sub-parameter (`:`).
+func (p *parser) nextParam(sub bool) {
+	if len(p.params) == 0 {
+		p.params = append(p.params, []int{-1})
+	}
+
+	if sub {
+		last := len(p.params) - 1
+		p.params[last] = append(p.params[last], -1)
+		return
+	}
+
+	if len(p.params) < maxParams {
+		p.params = append(p.params, []int{-1})
+	}
+}
+
+// feed advances the parser with a single rune, calling into the
+// terminal whenever something must be printed or executed.
+func (t *Terminal) feed(r rune) {
+	p := &t.parser
+
+	// C1 control characters (8-bit forms of ESC sequences).
+	if r >= 0x80 && r <= 0x9f && p.state != stateString && p.state != stateOSC {
+		switch r {
+		case 0x9b:
+			p.reset()
+			p.state = stateCSI
+		case 0x9d:
+			p.reset()
+			p.state = stateOSC
+		case 0x90, 0x98, 0x9e, 0x9f:
+			p.reset()
+			p.state = stateString
+		default:
+			p.state = stateGround
+			t.escDispatch(byte(r - 0x40))
+		}
+
+		return
+	}
+
+	switch p.state {
+	case stateGround:
+		if r < 0x20 || r == 0x7f {
+			t.execute(r)
+			return
+		}
+
+		t.print(r)
+	case stateEscape:
+		switch {
+		case r == 0x1b:
+			p.reset()
+		case r < 0x20:
+			t.execute(r)
+		case r == '[':
+			p.reset()
+			p.state = stateCSI
+		case r == ']':
+			p.reset()
+			p.state = stateOSC
+		case r == 'P' || r == 'X' || r == '^' || r == '_':
+			p.reset()
+			p.state = stateString
+		case r >= 0x20 && r <= 0x2f:
+			p.intermediates = append(p.intermediates, byte(r))
+			p.state = stateEscapeIntermediate
+		case r >= 0x30 && r <= 0x7e:
+			p.state = stateGround
+			t.escDispatch(byte(r))
+		default:
+			p.state = stateGround
+		}
+	case stateEscapeIntermediate:
+		switch {
+		case r == 0x1b:
+			p.reset()
+			p.state = stateEscape
+		case r < 0x20:
+			t.execute(r)
+		case r >= 0x20 && r <= 0x2f:
+			p.intermediates = append(p.intermediates, byte(r))
+		case r >= 0x30 && r <= 0x7e:
+			p.state = stateGround
+			t.escDispatch(byte(r))
+		default:
+			p.state = stateGround
+		}
+	case stateCSI:
+		switch {
+		case r == 0x1b:
+			p.reset()
+			p.state = stateEscape
+		case r == 0x18 || r == 0x1a:
+			p.state = stateGround
+		case r < 0x20:
+			t.execute(r)
+		case r >= '0' && r <= '9':
+			p.addDigit(r)
+		case r == ';' || r == ':':
+			p.nextParam(r == ':')
+		case r >= '<' && r <= '?':
+			if len(p.params) > 0 || len(p.intermediates) > 0 || p.private != 0 {
+				p.state = stateCSIIgnore
+				return
+			}
+
+			p.private = byte(r)
+		case r >= 0x20 && r <= 0x2f:
+			p.intermediates = append(p.intermediates, byte(r))
+		case r >= 0x40 && r <= 0x7e:
+			p.state = stateGround
+			t.csiDispatch(byte(r))
+		default:
+			p.state = stateCSIIgnore
+		}
+	case stateCSIIgnore:
+		switch {
+		case r == 0x1b:
+			p.reset()
+			p.state = stateEscape
+		case r < 0x20:
+			t.execute(r)
+		case r >= 0x40 && r <= 0x7e:
+			p.state = stateGround
+		}
+	case stateOSC:
+		switch {
+		case r == 0x07 || r == 0x9c:
+			p.state = stateGround
+			t.oscDispatch()
+		case p.escInString:
+			p.escInString = false
+			p.state = stateGround
+			t.oscDispatch()
+
+			if r != '\\' {
+				p.reset()
+				p.state = stateEscape
+				t.feed(r)
+			}
+		case r == 0x1b:
+			p.escInString = true
+		case r == 0x18 || r == 0x1a:
+			p.state = stateGround
+		case r < 0x20:
+		default:
+			if len(p.osc) < 4096 {
+				p.osc = append(p.osc, r)
+			}
+		}
+	case stateString:
+		switch {
+		case r == 0x9c || r == 0x18 || r == 0x1a:
+			p.state = stateGround
+		case p.escInString:
+			p.escInString = false
+			p.state = stateGround
+
+			if r != '\\' {
+				p.reset()
+				p.state = stateEscape
+				t.feed(r)
+			}
+		case r == 0x1b:
+			p.escInString = true
+		}
+	}
+}
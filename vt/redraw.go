@@ -0,0 +1,241 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// Redraw returns the text and escape sequences that bring a fresh
+// terminal of the same size to the current state of this one: the
+// contents and colors of both screens, which of them is active, the
+// cursor (position, pen and visibility, along with the ones saved with
+// DECSC), the scrolling region, tab stops, modes, character sets and
+// window title.
+//
+// The scrollback buffer isn't reproduced.
+func (t *Terminal) Redraw() string {
+	var buf strings.Builder
+
+	if t.title != "" {
+		buf.WriteString("\x1b]2;" + t.title + "\x07")
+	}
+
+	t.drawScreen(&buf, t.primary)
+
+	if t.screen == t.alternate {
+		// DECSET 1049 saves the cursor of the primary screen before
+		// switching to the cleared alternate screen.
+		saved := t.primary.saved
+		if !t.primary.hasSaved {
+			saved = cursor{}
+		}
+
+		buf.WriteString(SGR(saved.style))
+		buf.WriteString(cup(saved.x, saved.y))
+		buf.WriteString("\x1b[?1049h")
+
+		t.drawScreen(&buf, t.alternate)
+	}
+
+	if t.screen.hasSaved {
+		buf.WriteString(SGR(t.screen.saved.style))
+		buf.WriteString(cup(t.screen.saved.x, t.screen.saved.y))
+		buf.WriteString("\x1b7")
+	}
+
+	t.drawTabs(&buf)
+
+	if t.scrollTop != 0 || t.scrollBottom != t.height-1 {
+		buf.WriteString("\x1b[" + strconv.Itoa(t.scrollTop+1) + ";" + strconv.Itoa(t.scrollBottom+1) + "r")
+	}
+
+	t.drawCursor(&buf)
+
+	if t.cursor.originMode {
+		// DECOM moves the cursor home, so the cursor is moved back
+		// relative to the scrolling region.
+		buf.WriteString("\x1b[?6h")
+		buf.WriteString(cup(t.cursor.x, t.cursor.y-t.scrollTop))
+	}
+
+	for i, set := range t.cursor.charsets {
+		if set == charsetDECGraphics {
+			buf.WriteString("\x1b" + string("()"[i]) + "0")
+		}
+	}
+
+	if t.cursor.gl == 1 {
+		buf.WriteString("\x0e")
+	}
+
+	if !t.autowrap {
+		buf.WriteString("\x1b[?7l")
+	}
+
+	if t.insertMode {
+		buf.WriteString("\x1b[4h")
+	}
+
+	if t.newlineMode {
+		buf.WriteString("\x1b[20h")
+	}
+
+	if !t.cursorVisible {
+		buf.WriteString("\x1b[?25l")
+	}
+
+	return buf.String()
+}
+
+// drawScreen draws the lines of a screen, letting the lines that were
+// soft wrapped flow into the next ones so that they're still wrapped.
+//
+// Blank cells are erased (ECH) rather than printed, so that they stay
+// blank rather than holding spaces.
+func (t *Terminal) drawScreen(buf *strings.Builder, s *screen) {
+	var (
+		style   Style
+		flowing bool
+	)
+
+	pen := func(next Style) {
+		if next != style {
+			buf.WriteString(SGR(next))
+			style = next
+		}
+	}
+
+	for y, line := range s.lines {
+		end := len(line.Cells)
+		for end > 0 && isBlank(line.Cells[end-1]) {
+			end--
+		}
+
+		if end == 0 {
+			flowing = false
+			continue
+		}
+
+		// only printing a character carries out a pending wrap.
+		if !flowing || line.Cells[0].Rune == 0 {
+			buf.WriteString(cup(0, y))
+		}
+
+		for x := 0; x < end; {
+			cell := line.Cells[x]
+
+			if cell.Width == 0 {
+				x++
+				continue
+			}
+
+			if cell.Rune != 0 {
+				pen(cell.Style)
+				buf.WriteString(cell.String())
+				x++
+				continue
+			}
+
+			n := 1
+			for x+n < end && line.Cells[x+n].Rune == 0 && line.Cells[x+n].Width == 1 &&
+				line.Cells[x+n].Style == cell.Style {
+				n++
+			}
+
+			if (cell.Style != Style{}) {
+				pen(cell.Style)
+				buf.WriteString("\x1b[" + strconv.Itoa(n) + "X")
+			}
+
+			buf.WriteString("\x1b[" + strconv.Itoa(n) + "C")
+			x += n
+		}
+
+		// a line only flows into the next one if its last character
+		// got printed, leaving a wrap pending.
+		last := line.Cells[len(line.Cells)-1]
+		flowing = line.Wrapped && end == len(line.Cells) && y < len(s.lines)-1 &&
+			(last.Rune != 0 || last.Width == 0)
+	}
+
+	pen(Style{})
+}
+
+// drawTabs sets the tab stops, unless they're the default ones.
+func (t *Terminal) drawTabs(buf *strings.Builder) {
+	custom := false
+	for x, set := range t.tabs {
+		if set != (x > 0 && x%8 == 0) {
+			custom = true
+			break
+		}
+	}
+
+	if !custom {
+		return
+	}
+
+	buf.WriteString("\x1b[3g")
+
+	for x, set := range t.tabs {
+		if set {
+			buf.WriteString(cup(x, 0) + "\x1bH")
+		}
+	}
+}
+
+// drawCursor moves the cursor to its position and selects its pen. A
+// pending wrap is reproduced by printing the last character again.
+func (t *Terminal) drawCursor(buf *strings.Builder) {
+	c := t.cursor
+
+	if c.wrapPending {
+		x := c.x
+		line := t.screen.lines[c.y]
+		if line.Cells[x].Width == 0 && x > 0 {
+			x--
+		}
+
+		cell := line.Cells[x]
+		buf.WriteString(cup(x, c.y))
+		buf.WriteString(SGR(cell.Style))
+		buf.WriteString(cell.String())
+	} else {
+		buf.WriteString(cup(c.x, c.y))
+	}
+
+	buf.WriteString(SGR(c.style))
+}
+
+// isBlank tells whether a cell is empty and drawn with the default
+// style, just like the cells of a fresh screen.
+func isBlank(cell Cell) bool {
+	return cell.Width == 1 && cell.Rune == 0 && cell.Style == Style{}
+}
+
+// cup returns the sequence moving the cursor to a zero-based position.
+func cup(x, y int) string {
+	return "\x1b[" + strconv.Itoa(y+1) + ";" + strconv.Itoa(x+1) + "H"
+}
+
+// Restore plays `events` into a fresh terminal with the size declared in
+// `header`, returning an output event (at time 0) that resets the
+// terminal (RIS) and then redraws the resulting state (see
+// `Terminal.Redraw`), so that it can be played at any point of a cast.
+//
+// It's meant to be given to `cast.ExtractRange` and
+// `cast.CutRangePreserving`.
+func Restore(header cast.Header, events []*cast.Event) ([]*cast.Event, error) {
+	t := NewFromHeader(&header)
+
+	for _, ev := range events {
+		err := t.Apply(ev)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return []*cast.Event{{Time: 0, Type: "o", Data: "\x1bc" + t.Redraw()}}, nil
+}
@@ -0,0 +1,93 @@
+package vt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestRedraw(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		output string
+	}{
+		{
+			desc:   "fresh terminal",
+			output: "",
+		},
+		{
+			desc:   "colors and attributes",
+			output: "$ \x1b[1;31mls\x1b[0m\r\n\x1b[44mblue\x1b[K\x1b[0m\r\n\x1b[38;2;1;2;3;4mrgb",
+		},
+		{
+			desc:   "wrapped lines and pending wrap",
+			output: "0123456789abcdefghijklmnopqrst",
+		},
+		{
+			desc:   "wide characters and combining marks",
+			output: "日本語 é",
+		},
+		{
+			desc:   "alternate screen",
+			output: "prompt$ vim\x1b7\x1b[?1049h\x1b[2;3Hediting\x1b[?25l\x1b]2;vim\x07",
+		},
+		{
+			desc:   "scrolling region and modes",
+			output: "\x1b[2;4r\x1b[?6h\x1b[2;2Hx\x1b[4h\x1b[?7l\x1b(0q\x1b[3g\x1b[1;5H\x1bH",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			original := vt.New(10, 5)
+			original.WriteString(tc.output)
+
+			redrawn := vt.New(10, 5)
+			redrawn.WriteString(original.Redraw())
+
+			assert.True(t, original.Snapshot().Equal(redrawn.Snapshot()))
+			assert.Equal(t, original.Redraw(), redrawn.Redraw())
+
+			for y := 0; y < 5; y++ {
+				assert.Equal(t, original.Line(y).Wrapped, redrawn.Line(y).Wrapped)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	header := cast.Header{Version: 2, Width: 10, Height: 5}
+
+	t.Run("Resets the terminal", func(t *testing.T) {
+		term := vt.New(10, 5)
+		term.WriteString("\x1b[?1049hleftover\x1b[?25l")
+
+		events, err := vt.Restore(header, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "i", Data: "x"},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		term.WriteString(events[0].Data)
+		assert.True(t, vt.New(10, 5).Snapshot().Equal(term.Snapshot()))
+	})
+
+	t.Run("Redraws the screen", func(t *testing.T) {
+		events, err := vt.Restore(header, []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "hello"},
+			{Time: cast.Seconds(2), Type: "r", Data: "20x3"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\r\nworld"},
+		})
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+		assert.Equal(t, cast.Time(0), events[0].Time)
+
+		term := vt.New(20, 3)
+		term.WriteString("garbage")
+		term.WriteString(events[0].Data)
+		assert.Equal(t, "hello\nworld\n", term.String())
+	})
+}
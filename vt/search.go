@@ -0,0 +1,110 @@
+package vt
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// Match is a piece of text that became visible on the screen while
+// playing a cast.
+type Match struct {
+	// Time and Index identify the event after which the text became
+	// visible.
+	Time  cast.Time
+	Index int
+
+	// Row is the row of the screen that the text is on (the first row
+	// of the line if it got wrapped).
+	Row int
+
+	// Text is the text that matched and Line the whole line holding it.
+	Text string
+	Line string
+}
+
+// matchKey identifies a match on the screen, regardless of the lines
+// that scrolled off the screen since it appeared.
+type matchKey struct {
+	alt    bool
+	row    int
+	offset int
+	text   string
+}
+
+// Search plays the events of a cast into a fresh terminal, looking for
+// `pattern` on the screen after every event. Each match is reported once,
+// after the event that made it visible: text that was printed across
+// several events is matched once complete, and text that stays on the
+// screen (even if it scrolls) isn't reported again.
+//
+// Matches don't span lines, except for lines that got wrapped at the
+// right margin.
+func Search(c *cast.Cast, pattern *regexp.Regexp) ([]Match, error) {
+	if pattern == nil {
+		return nil, errors.Errorf("pattern must not be nil")
+	}
+
+	t, err := newForCast(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		matches []Match
+		visible = map[matchKey]bool{}
+	)
+
+	for idx, ev := range c.EventStream {
+		if ev.Type != "o" && ev.Type != "r" {
+			continue
+		}
+
+		err = t.Apply(ev)
+		if err != nil {
+			return nil, err
+		}
+
+		// rows of the primary screen are numbered since the beginning
+		// of the recording so that scrolling doesn't move matches.
+		scrolled := 0
+		if !t.AltScreen() {
+			scrolled = t.Scrolled()
+		}
+
+		current := map[matchKey]bool{}
+
+		for _, line := range joinWrapped(t.screen.lines) {
+			for _, loc := range pattern.FindAllStringIndex(line.text, -1) {
+				if loc[0] == loc[1] {
+					continue
+				}
+
+				key := matchKey{
+					alt:    t.AltScreen(),
+					row:    scrolled + line.row,
+					offset: loc[0],
+					text:   line.text[loc[0]:loc[1]],
+				}
+
+				current[key] = true
+				if visible[key] {
+					continue
+				}
+
+				matches = append(matches, Match{
+					Time:  ev.Time,
+					Index: idx,
+					Row:   line.row,
+					Text:  key.text,
+					Line:  line.text,
+				})
+			}
+		}
+
+		visible = current
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,68 @@
+package vt_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestSearch(t *testing.T) {
+	search := func(pattern string, events ...*cast.Event) []vt.Match {
+		matches, err := vt.Search(&cast.Cast{
+			Header:      cast.Header{Version: 2, Width: 5, Height: 2},
+			EventStream: events,
+		}, regexp.MustCompile(pattern))
+		assert.NoError(t, err)
+
+		return matches
+	}
+
+	t.Run("Fails without a pattern", func(t *testing.T) {
+		_, err := vt.Search(&cast.Cast{
+			Header: cast.Header{Version: 2, Width: 5, Height: 2},
+		}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Matches text split across events", func(t *testing.T) {
+		assert.Equal(t, []vt.Match{
+			{Time: cast.Seconds(3), Index: 2, Row: 0, Text: "done", Line: "done!"},
+		}, search("done",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "do"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "\x1b[1m"},
+			&cast.Event{Time: cast.Seconds(3), Type: "o", Data: "ne!"},
+		))
+	})
+
+	t.Run("Reports text once while it stays visible", func(t *testing.T) {
+		matches := search("ok",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "ok"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "\r\n\r\n\r\n"},
+			&cast.Event{Time: cast.Seconds(3), Type: "o", Data: "\x1b[Hok"},
+			&cast.Event{Time: cast.Seconds(4), Type: "o", Data: "\x1b[2J"},
+			&cast.Event{Time: cast.Seconds(5), Type: "i", Data: "ok"},
+			&cast.Event{Time: cast.Seconds(6), Type: "o", Data: "\x1b[2;2Hok"},
+		)
+
+		var times []cast.Time
+		for _, match := range matches {
+			times = append(times, match.Time)
+		}
+
+		assert.Equal(t, []cast.Time{cast.Seconds(1), cast.Seconds(3), cast.Seconds(6)}, times)
+	})
+
+	t.Run("Matches wrapped lines", func(t *testing.T) {
+		matches := search("c+",
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "abcccc"},
+		)
+
+		assert.Equal(t, []vt.Match{
+			{Time: cast.Seconds(1), Index: 0, Row: 0, Text: "cccc", Line: "abcccc"},
+		}, matches)
+	})
+}
@@ -0,0 +1,140 @@
+package vt
+
+// sgr applies a SELECT GRAPHIC RENDITION control sequence to the pen.
+func (t *Terminal) sgr() {
+	var (
+		p     = &t.parser
+		style = &t.cursor.style
+	)
+
+	if len(p.params) == 0 {
+		*style = Style{}
+		return
+	}
+
+	for i := 0; i < len(p.params); i++ {
+		param := p.params[i]
+
+		switch code := p.rawParam(i, 0); {
+		case code == 0:
+			*style = Style{}
+		case code == 1:
+			style.Attrs |= AttrBold
+		case code == 2:
+			style.Attrs |= AttrFaint
+		case code == 3:
+			style.Attrs |= AttrItalic
+		case code == 4:
+			if len(param) > 1 && param[1] == 0 {
+				style.Attrs &^= AttrUnderline
+			} else {
+				style.Attrs |= AttrUnderline
+			}
+		case code == 5 || code == 6:
+			style.Attrs |= AttrBlink
+		case code == 7:
+			style.Attrs |= AttrInverse
+		case code == 8:
+			style.Attrs |= AttrHidden
+		case code == 9:
+			style.Attrs |= AttrStrikethrough
+		case code == 21:
+			style.Attrs |= AttrUnderline
+		case code == 22:
+			style.Attrs &^= AttrBold | AttrFaint
+		case code == 23:
+			style.Attrs &^= AttrItalic
+		case code == 24:
+			style.Attrs &^= AttrUnderline
+		case code == 25:
+			style.Attrs &^= AttrBlink
+		case code == 27:
+			style.Attrs &^= AttrInverse
+		case code == 28:
+			style.Attrs &^= AttrHidden
+		case code == 29:
+			style.Attrs &^= AttrStrikethrough
+		case code >= 30 && code <= 37:
+			style.Fg = IndexedColor(uint8(code - 30))
+		case code == 38:
+			var color Color
+			color, i = t.extendedColor(i)
+			style.Fg = color
+		case code == 39:
+			style.Fg = DefaultColor
+		case code >= 40 && code <= 47:
+			style.Bg = IndexedColor(uint8(code - 40))
+		case code == 48:
+			var color Color
+			color, i = t.extendedColor(i)
+			style.Bg = color
+		case code == 49:
+			style.Bg = DefaultColor
+		case code >= 90 && code <= 97:
+			style.Fg = IndexedColor(uint8(code - 90 + 8))
+		case code >= 100 && code <= 107:
+			style.Bg = IndexedColor(uint8(code - 100 + 8))
+		}
+	}
+}
+
+// extendedColor parses the color of an SGR 38 or 48 parameter at `idx`,
+// either in its colon form (`38:5:n`, `38:2::r:g:b`) or in its
+// semicolon form (`38;5;n`, `38;2;r;g;b`). It returns the color along
+// with the index of the last parameter consumed.
+func (t *Terminal) extendedColor(idx int) (Color, int) {
+	p := &t.parser
+
+	// colon form: everything lives in the sub-parameters.
+	if sub := p.params[idx]; len(sub) > 1 {
+		switch sub[1] {
+		case 5:
+			if len(sub) > 2 {
+				return IndexedColor(uint8(clamp(sub[2], 0, 255))), idx
+			}
+		case 2:
+			rgb := sub[2:]
+			if len(rgb) > 3 {
+				// skip the color space identifier.
+				rgb = rgb[1:]
+			}
+
+			if len(rgb) == 3 {
+				return RGBColor(component(rgb[0]), component(rgb[1]), component(rgb[2])), idx
+			}
+		}
+
+		return t.currentColor(idx), idx
+	}
+
+	switch p.rawParam(idx+1, -1) {
+	case 5:
+		if idx+2 < len(p.params) {
+			return IndexedColor(uint8(clamp(p.rawParam(idx+2, 0), 0, 255))), idx + 2
+		}
+	case 2:
+		if idx+4 < len(p.params) {
+			return RGBColor(
+				component(p.rawParam(idx+2, 0)),
+				component(p.rawParam(idx+3, 0)),
+				component(p.rawParam(idx+4, 0)),
+			), idx + 4
+		}
+	}
+
+	return t.currentColor(idx), len(p.params)
+}
+
+// currentColor returns the color that a malformed SGR 38 or 48 leaves
+// untouched.
+func (t *Terminal) currentColor(idx int) Color {
+	if t.parser.rawParam(idx, 0) == 38 {
+		return t.cursor.style.Fg
+	}
+
+	return t.cursor.style.Bg
+}
+
+func component(value int) uint8 {
+	return uint8(clamp(value, 0, 255))
+}
@@ -0,0 +1,202 @@
+package vt
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// Snapshot is a copy of the state of a terminal's active screen at a
+// given point in time.
+type Snapshot struct {
+	// Time is the timestamp that the snapshot corresponds to.
+	Time cast.Time
+
+	Width  int
+	Height int
+
+	// Lines holds the rows of the active screen, top to bottom.
+	Lines []Line
+
+	CursorX       int
+	CursorY       int
+	CursorVisible bool
+
+	// AltScreen tells whether the alternate screen was active.
+	AltScreen bool
+
+	Title string
+}
+
+// Snapshot copies the current state of the terminal's active screen.
+func (t *Terminal) Snapshot() *Snapshot {
+	lines := make([]Line, len(t.screen.lines))
+	for i, line := range t.screen.lines {
+		lines[i] = line.clone()
+	}
+
+	return &Snapshot{
+		Width:         t.width,
+		Height:        t.height,
+		Lines:         lines,
+		CursorX:       t.cursor.x,
+		CursorY:       t.cursor.y,
+		CursorVisible: t.cursorVisible,
+		AltScreen:     t.AltScreen(),
+		Title:         t.title,
+	}
+}
+
+// Text returns the text of the snapshot, one line per row and without
+// trailing blanks.
+func (s *Snapshot) Text() string {
+	rows := make([]string, len(s.Lines))
+	for i, line := range s.Lines {
+		rows[i] = line.String()
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// NewFromHeader creates a terminal with the dimensions declared in a
+// cast header.
+func NewFromHeader(header *cast.Header) *Terminal {
+	return New(int(header.Width), int(header.Height))
+}
+
+// Apply feeds an event of a cast to the terminal: output (`o`) is
+// interpreted and resize events (`r`) change the terminal's size. Any
+// other event doesn't affect the screen.
+//
+// Lines that the event prints text on for the first time get its
+// timestamp.
+func (t *Terminal) Apply(ev *cast.Event) error {
+	t.now = ev.Time
+
+	switch ev.Type {
+	case "o":
+		t.WriteString(ev.Data)
+	case "r":
+		width, height, err := cast.ParseSize(ev.Data)
+		if err != nil {
+			return errors.Wrapf(err,
+				"invalid resize event at %v", ev.Time)
+		}
+
+		t.Resize(width, height)
+	}
+
+	return nil
+}
+
+// newForCast creates a terminal to play the events of a cast into.
+func newForCast(c *cast.Cast) (*Terminal, error) {
+	if c == nil {
+		return nil, errors.Errorf("cast must not be nil")
+	}
+
+	if c.Header.Width == 0 || c.Header.Height == 0 {
+		return nil, errors.Errorf("cast header must declare its dimensions")
+	}
+
+	return NewFromHeader(&c.Header), nil
+}
+
+// Replay plays the events of a cast into a fresh terminal up to (and
+// including) those happening at `at`, returning the state of the screen
+// at that point.
+func Replay(c *cast.Cast, at cast.Time) (*Snapshot, error) {
+	t, err := newForCast(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ev := range c.EventStream {
+		if ev.Time > at {
+			break
+		}
+
+		err = t.Apply(ev)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := t.Snapshot()
+	snapshot.Time = at
+
+	return snapshot, nil
+}
+
+// Equal tells whether two snapshots show the same screen (and cursor),
+// regardless of their timestamps.
+func (s *Snapshot) Equal(other *Snapshot) bool {
+	if s.Width != other.Width || s.Height != other.Height ||
+		s.CursorX != other.CursorX || s.CursorY != other.CursorY ||
+		s.CursorVisible != other.CursorVisible ||
+		len(s.Lines) != len(other.Lines) {
+		return false
+	}
+
+	for i, line := range s.Lines {
+		if len(line.Cells) != len(other.Lines[i].Cells) {
+			return false
+		}
+
+		for j, cell := range line.Cells {
+			if cell != other.Lines[i].Cells[j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Frames plays the events of a cast into a fresh terminal, calling `fn`
+// with a snapshot of the screen at the beginning of the recording and
+// then every time the screen changes.
+//
+// Events sharing a timestamp are applied together, so that only the
+// final state of the screen at any given time makes a frame.
+func Frames(c *cast.Cast, fn func(s *Snapshot) error) error {
+	t, err := newForCast(c)
+	if err != nil {
+		return err
+	}
+
+	last := t.Snapshot()
+
+	err = fn(last)
+	if err != nil {
+		return err
+	}
+
+	for idx, ev := range c.EventStream {
+		err = t.Apply(ev)
+		if err != nil {
+			return err
+		}
+
+		next := idx + 1
+		if next < len(c.EventStream) && c.EventStream[next].Time == ev.Time {
+			continue
+		}
+
+		snapshot := t.Snapshot()
+		if snapshot.Equal(last) {
+			continue
+		}
+
+		snapshot.Time = ev.Time
+		last = snapshot
+
+		err = fn(snapshot)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package vt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestReplay(t *testing.T) {
+	c := &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 10, Height: 2},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "first"},
+			{Time: cast.Seconds(2), Type: "i", Data: "ignored"},
+			{Time: cast.Seconds(2), Type: "r", Data: "4x2"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\x1b]2;title\x07\r\nxyz"},
+		},
+	}
+
+	t.Run("Fails without a cast", func(t *testing.T) {
+		_, err := vt.Replay(nil, cast.Seconds(1))
+		assert.Error(t, err)
+	})
+
+	t.Run("Captures the screen before anything happened", func(t *testing.T) {
+		snapshot, err := vt.Replay(c, cast.Seconds(0.5))
+		assert.NoError(t, err)
+		assert.Equal(t, "\n", snapshot.Text())
+		assert.Equal(t, 10, snapshot.Width)
+	})
+
+	t.Run("Includes events happening at the timestamp", func(t *testing.T) {
+		snapshot, err := vt.Replay(c, cast.Seconds(2))
+		assert.NoError(t, err)
+		assert.Equal(t, "firs\n", snapshot.Text())
+		assert.Equal(t, 4, snapshot.Width)
+		assert.Equal(t, 2, snapshot.Height)
+	})
+
+	t.Run("Captures cursor and title", func(t *testing.T) {
+		snapshot, err := vt.Replay(c, cast.Seconds(10))
+		assert.NoError(t, err)
+		assert.Equal(t, "firs\nxyz", snapshot.Text())
+		assert.Equal(t, 3, snapshot.CursorX)
+		assert.Equal(t, 1, snapshot.CursorY)
+		assert.True(t, snapshot.CursorVisible)
+		assert.Equal(t, "title", snapshot.Title)
+		assert.Equal(t, cast.Seconds(10), snapshot.Time)
+	})
+
+	t.Run("Fails on malformed resize events", func(t *testing.T) {
+		_, err := vt.Replay(&cast.Cast{
+			Header: cast.Header{Version: 2, Width: 10, Height: 2},
+			EventStream: []*cast.Event{
+				{Time: cast.Seconds(1), Type: "r", Data: "wide"},
+			},
+		}, cast.Seconds(1))
+		assert.Error(t, err)
+	})
+}
+
+func TestSnapshot_ANSI(t *testing.T) {
+	term := vt.New(10, 2)
+	term.WriteString("a\x1b[1;31mb\x1b[38;5;100;48;2;1;2;3mc\x1b[0m  \r\n\x1b[44m \x1b[0m")
+
+	assert.Equal(t,
+		"a\x1b[0;1;31mb\x1b[0;1;38;5;100;48;2;1;2;3mc\x1b[0m\n"+
+			"\x1b[0;44m \x1b[0m",
+		term.Snapshot().ANSI())
+}
+
+func TestFrames(t *testing.T) {
+	c := &cast.Cast{
+		Header: cast.Header{Version: 2, Width: 5, Height: 1},
+		EventStream: []*cast.Event{
+			{Time: cast.Seconds(1), Type: "o", Data: "a"},
+			{Time: cast.Seconds(1), Type: "o", Data: "b"},
+			{Time: cast.Seconds(2), Type: "i", Data: "ignored"},
+			{Time: cast.Seconds(3), Type: "o", Data: "\x1b[0m"},
+			{Time: cast.Seconds(4), Type: "o", Data: "c"},
+		},
+	}
+
+	var (
+		times []cast.Time
+		texts []string
+	)
+
+	err := vt.Frames(c, func(s *vt.Snapshot) error {
+		times = append(times, s.Time)
+		texts = append(texts, s.Text())
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []cast.Time{0, cast.Seconds(1), cast.Seconds(4)}, times)
+	assert.Equal(t, []string{"", "ab", "abc"}, texts)
+
+	assert.Error(t, vt.Frames(nil, func(*vt.Snapshot) error { return nil }))
+}
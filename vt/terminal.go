@@ -0,0 +1,348 @@
+// Package vt implements a headless virtual terminal that interprets the
+// output of a cast (text and xterm escape sequences), so that the state
+// of the screen can be inspected at any point of a recording.
+package vt
+
+import (
+	"strings"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// DefaultScrollback is the number of lines that scrolled off the top of
+// the primary screen that a terminal keeps by default.
+const DefaultScrollback = 10000
+
+// cursor holds the position of the cursor along with the state that
+// DECSC saves and DECRC restores.
+type cursor struct {
+	x, y  int
+	style Style
+
+	// wrapPending indicates that the last character was printed at the
+	// right margin, so that the next one goes to the following line.
+	wrapPending bool
+
+	originMode bool
+	charsets   [2]charset
+	gl         int
+}
+
+// charset is a character set designated to G0 or G1.
+type charset uint8
+
+const (
+	charsetASCII charset = iota
+	charsetDECGraphics
+)
+
+// screen is one of the two screens of the terminal (primary or
+// alternate).
+type screen struct {
+	lines []Line
+
+	saved    cursor
+	hasSaved bool
+}
+
+func newScreen(width, height int) *screen {
+	s := &screen{lines: make([]Line, height)}
+	for i := range s.lines {
+		s.lines[i] = newLine(width, Style{})
+	}
+
+	return s
+}
+
+// Terminal is a headless xterm-compatible terminal: it interprets the
+// text and escape sequences written to it, keeping a model of the
+// screen that can be inspected at any point (see `Snapshot`).
+type Terminal struct {
+	width, height int
+
+	primary   *screen
+	alternate *screen
+	screen    *screen
+
+	cursor        cursor
+	cursorVisible bool
+	autowrap      bool
+	insertMode    bool
+	newlineMode   bool
+
+	// scrollTop and scrollBottom delimit the scrolling region (both
+	// included).
+	scrollTop, scrollBottom int
+
+	tabs []bool
+
+	// scrollback holds the lines that scrolled off the top of the
+	// primary screen, oldest first.
+	scrollback      []Line
+	scrollbackLimit int
+
+	// scrolled counts the lines that ever scrolled off the top of the
+	// primary screen.
+	scrolled int
+
+	title    string
+	lastRune rune
+
+	// now is the timestamp of the event being applied.
+	now cast.Time
+
+	// keepHistory makes clearing the primary screen move its lines to
+	// the scrollback buffer.
+	keepHistory bool
+
+	parser parser
+}
+
+// New creates a terminal of `width` columns and `height` rows.
+func New(width, height int) *Terminal {
+	if width < 1 {
+		width = 1
+	}
+
+	if height < 1 {
+		height = 1
+	}
+
+	t := &Terminal{
+		scrollbackLimit: DefaultScrollback,
+	}
+
+	t.width = width
+	t.height = height
+	t.reset()
+
+	return t
+}
+
+// reset brings the terminal back to its initial state (RIS).
+func (t *Terminal) reset() {
+	t.primary = newScreen(t.width, t.height)
+	t.alternate = newScreen(t.width, t.height)
+	t.screen = t.primary
+	t.cursor = cursor{}
+	t.cursorVisible = true
+	t.autowrap = true
+	t.insertMode = false
+	t.newlineMode = false
+	t.scrollTop = 0
+	t.scrollBottom = t.height - 1
+	t.title = ""
+	t.resetTabs()
+}
+
+func (t *Terminal) resetTabs() {
+	t.tabs = make([]bool, t.width)
+	for i := 8; i < t.width; i += 8 {
+		t.tabs[i] = true
+	}
+}
+
+// SetScrollbackLimit sets the maximum number of lines kept in the
+// scrollback buffer. Zero disables the scrollback buffer.
+func (t *Terminal) SetScrollbackLimit(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	t.scrollbackLimit = limit
+	t.trimScrollback()
+}
+
+// SetKeepHistory makes the terminal keep everything that was shown on
+// the primary screen: clearing (or resetting) the screen moves its lines
+// to the scrollback buffer, as some terminals do, and requests to erase
+// the scrollback buffer are ignored.
+func (t *Terminal) SetKeepHistory(keep bool) {
+	t.keepHistory = keep
+}
+
+// Width returns the number of columns of the terminal.
+func (t *Terminal) Width() int {
+	return t.width
+}
+
+// Height returns the number of rows of the terminal.
+func (t *Terminal) Height() int {
+	return t.height
+}
+
+// Title returns the window title set through OSC 0 or 2.
+func (t *Terminal) Title() string {
+	return t.title
+}
+
+// AltScreen tells whether the alternate screen is active.
+func (t *Terminal) AltScreen() bool {
+	return t.screen == t.alternate
+}
+
+// Cursor returns the position of the cursor (zero-based).
+func (t *Terminal) Cursor() (x, y int) {
+	return t.cursor.x, t.cursor.y
+}
+
+// Write interprets `data` as output of a program running in the
+// terminal. It never fails.
+func (t *Terminal) Write(data []byte) (int, error) {
+	t.WriteString(string(data))
+	return len(data), nil
+}
+
+// WriteString interprets `data` as output of a program running in the
+// terminal.
+func (t *Terminal) WriteString(data string) {
+	for _, r := range data {
+		t.feed(r)
+	}
+}
+
+// Resize changes the size of the terminal, truncating (or padding) the
+// lines of both screens. Lines that no longer fit in the primary screen
+// are moved to the scrollback buffer, starting from the top, while
+// making sure that the cursor stays on the screen.
+func (t *Terminal) Resize(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+
+	if height < 1 {
+		height = 1
+	}
+
+	if width == t.width && height == t.height {
+		return
+	}
+
+	for _, s := range []*screen{t.primary, t.alternate} {
+		cursorY := t.cursor.y
+		if s != t.screen {
+			cursorY = s.saved.y
+		}
+
+		for len(s.lines) > height {
+			// drop blank lines below the cursor before scrolling
+			// lines off the top.
+			last := len(s.lines) - 1
+			if last > cursorY && s.lines[last].String() == "" {
+				s.lines = s.lines[:last]
+				continue
+			}
+
+			if s == t.primary {
+				t.pushScrollback(s.lines[0])
+			}
+
+			s.lines = s.lines[1:]
+			cursorY--
+
+			if s == t.screen {
+				t.cursor.y--
+			} else {
+				s.saved.y--
+			}
+		}
+
+		for len(s.lines) < height {
+			s.lines = append(s.lines, newLine(width, Style{}))
+		}
+
+		for i := range s.lines {
+			s.lines[i].resize(width)
+		}
+	}
+
+	t.width = width
+	t.height = height
+	t.scrollTop = 0
+	t.scrollBottom = height - 1
+	t.resetTabs()
+
+	t.cursor.wrapPending = false
+	t.cursor.x = clamp(t.cursor.x, 0, width-1)
+	t.cursor.y = clamp(t.cursor.y, 0, height-1)
+
+	for _, s := range []*screen{t.primary, t.alternate} {
+		s.saved.x = clamp(s.saved.x, 0, width-1)
+		s.saved.y = clamp(s.saved.y, 0, height-1)
+	}
+}
+
+func (t *Terminal) pushScrollback(line Line) {
+	t.scrolled++
+
+	if t.scrollbackLimit == 0 {
+		return
+	}
+
+	t.scrollback = append(t.scrollback, line)
+	t.trimScrollback()
+}
+
+// saveScreen moves the lines of the primary screen, up to the last one
+// holding text, to the scrollback buffer when keeping the history.
+func (t *Terminal) saveScreen() {
+	if !t.keepHistory {
+		return
+	}
+
+	last := len(t.primary.lines) - 1
+	for last >= 0 && t.primary.lines[last].String() == "" {
+		last--
+	}
+
+	for _, line := range t.primary.lines[:last+1] {
+		t.pushScrollback(line.clone())
+	}
+}
+
+func (t *Terminal) trimScrollback() {
+	if excess := len(t.scrollback) - t.scrollbackLimit; excess > 0 {
+		t.scrollback = append(t.scrollback[:0:0], t.scrollback[excess:]...)
+	}
+}
+
+// Scrollback returns the lines that scrolled off the top of the primary
+// screen, oldest first.
+func (t *Terminal) Scrollback() []Line {
+	return t.scrollback
+}
+
+// Scrolled returns the number of lines that ever scrolled off the top
+// of the primary screen (including the ones that no longer fit in the
+// scrollback buffer).
+func (t *Terminal) Scrolled() int {
+	return t.scrolled
+}
+
+// Line returns the `y`-th line of the active screen.
+func (t *Terminal) Line(y int) Line {
+	return t.screen.lines[y]
+}
+
+// String returns the text of the active screen, one line per row and
+// without trailing blanks.
+func (t *Terminal) String() string {
+	rows := make([]string, len(t.screen.lines))
+	for i, line := range t.screen.lines {
+		rows[i] = line.String()
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+
+	if value > max {
+		return max
+	}
+
+	return value
+}
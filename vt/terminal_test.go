@@ -0,0 +1,289 @@
+package vt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestTerminal_Print(t *testing.T) {
+	t.Run("Prints text and moves the cursor", func(t *testing.T) {
+		term := vt.New(10, 3)
+		term.WriteString("hello\r\nworld")
+
+		assert.Equal(t, "hello\nworld\n", term.String())
+
+		x, y := term.Cursor()
+		assert.Equal(t, 5, x)
+		assert.Equal(t, 1, y)
+	})
+
+	t.Run("Wraps at the right margin", func(t *testing.T) {
+		term := vt.New(4, 3)
+		term.WriteString("abcdef")
+
+		assert.Equal(t, "abcd\nef\n", term.String())
+		assert.True(t, term.Line(0).Wrapped)
+		assert.False(t, term.Line(1).Wrapped)
+	})
+
+	t.Run("Doesn't wrap with autowrap disabled", func(t *testing.T) {
+		term := vt.New(4, 2)
+		term.WriteString("\x1b[?7labcdef")
+
+		assert.Equal(t, "abcf\n", term.String())
+	})
+
+	t.Run("Scrolls lines into the scrollback", func(t *testing.T) {
+		term := vt.New(5, 2)
+		term.WriteString("1\r\n2\r\n3\r\n4")
+
+		assert.Equal(t, "3\n4", term.String())
+		assert.Equal(t, 2, term.Scrolled())
+		assert.Len(t, term.Scrollback(), 2)
+		assert.Equal(t, "1", term.Scrollback()[0].String())
+	})
+
+	t.Run("Limits the scrollback", func(t *testing.T) {
+		term := vt.New(5, 1)
+		term.SetScrollbackLimit(1)
+		term.WriteString("1\r\n2\r\n3")
+
+		assert.Equal(t, 2, term.Scrolled())
+		assert.Len(t, term.Scrollback(), 1)
+		assert.Equal(t, "2", term.Scrollback()[0].String())
+	})
+
+	t.Run("Handles wide characters", func(t *testing.T) {
+		term := vt.New(5, 2)
+		term.WriteString("ab日本")
+
+		assert.Equal(t, "ab日\n本", term.String())
+
+		line := term.Line(0)
+		assert.Equal(t, uint8(2), line.Cells[2].Width)
+		assert.Equal(t, uint8(0), line.Cells[3].Width)
+	})
+
+	t.Run("Attaches combining characters", func(t *testing.T) {
+		term := vt.New(5, 1)
+		term.WriteString("éx")
+
+		assert.Equal(t, "éx", term.String())
+
+		x, _ := term.Cursor()
+		assert.Equal(t, 2, x)
+	})
+
+	t.Run("Translates DEC line drawing characters", func(t *testing.T) {
+		term := vt.New(5, 1)
+		term.WriteString("\x1b(0lqk\x1b(Bq")
+
+		assert.Equal(t, "┌─┐q", term.String())
+	})
+}
+
+func TestTerminal_ControlSequences(t *testing.T) {
+	var cases = []struct {
+		description string
+		width       int
+		height      int
+		input       string
+		expected    string
+	}{
+		{
+			description: "moves the cursor to absolute positions",
+			width:       5, height: 3,
+			input:    "\x1b[2;3Hx\x1b[Hy",
+			expected: "y\n  x\n",
+		},
+		{
+			description: "moves the cursor relatively",
+			width:       5, height: 3,
+			input:    "\x1b[2B\x1b[3Cx\x1b[2A\x1b[2Dy",
+			expected: "  y\n\n   x",
+		},
+		{
+			description: "erases the rest of the line",
+			width:       5, height: 1,
+			input:    "abcde\x1b[3G\x1b[K",
+			expected: "ab",
+		},
+		{
+			description: "erases the display",
+			width:       5, height: 2,
+			input:    "abc\r\ndef\x1b[2J",
+			expected: "\n",
+		},
+		{
+			description: "inserts and deletes characters",
+			width:       6, height: 1,
+			input:    "abcd\x1b[2G\x1b[2@\x1b[4G\x1b[P",
+			expected: "a  cd",
+		},
+		{
+			description: "erases characters",
+			width:       5, height: 1,
+			input:    "abcde\x1b[2G\x1b[2X",
+			expected: "a  de",
+		},
+		{
+			description: "inserts and deletes lines",
+			width:       3, height: 3,
+			input:    "a\r\nb\r\nc\x1b[2H\x1b[M\x1b[H\x1b[L",
+			expected: "\na\nc",
+		},
+		{
+			description: "scrolls within the scrolling region",
+			width:       3, height: 4,
+			input:    "a\r\nb\r\nc\r\nd\x1b[2;3r\x1b[3H\n",
+			expected: "a\nc\n\nd",
+		},
+		{
+			description: "reverse indexes at the top of the region",
+			width:       3, height: 3,
+			input:    "a\r\nb\x1b[H\x1bMc",
+			expected: "c\na\nb",
+		},
+		{
+			description: "moves to tab stops",
+			width:       20, height: 1,
+			input:    "a\tb",
+			expected: "a       b",
+		},
+		{
+			description: "repeats the last character",
+			width:       5, height: 1,
+			input:    "a\x1b[3b",
+			expected: "aaaa",
+		},
+		{
+			description: "saves and restores the cursor",
+			width:       5, height: 2,
+			input:    "ab\x1b7\r\ncd\x1b8e",
+			expected: "abe\ncd",
+		},
+		{
+			description: "inserts in insert mode",
+			width:       5, height: 1,
+			input:    "ac\x1b[D\x1b[4hb\x1b[4lx",
+			expected: "abx",
+		},
+		{
+			description: "ignores unknown sequences",
+			width:       5, height: 1,
+			input:    "a\x1b[>4;1m\x1b[?2004h\x1bP+q\x1b\\b",
+			expected: "ab",
+		},
+		{
+			description: "resets the terminal",
+			width:       5, height: 1,
+			input:    "abc\x1bcd",
+			expected: "d",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			term := vt.New(tc.width, tc.height)
+			term.WriteString(tc.input)
+
+			assert.Equal(t, tc.expected, term.String())
+		})
+	}
+}
+
+func TestTerminal_SGR(t *testing.T) {
+	t.Run("Sets colors and attributes", func(t *testing.T) {
+		term := vt.New(10, 1)
+		term.WriteString("\x1b[1;31;44ma\x1b[38;5;200;48;2;1;2;3mb\x1b[38:2::4:5:6mc\x1b[0md")
+
+		cells := term.Line(0).Cells
+
+		assert.Equal(t, vt.Style{
+			Fg:    vt.IndexedColor(1),
+			Bg:    vt.IndexedColor(4),
+			Attrs: vt.AttrBold,
+		}, cells[0].Style)
+		assert.Equal(t, vt.Style{
+			Fg:    vt.IndexedColor(200),
+			Bg:    vt.RGBColor(1, 2, 3),
+			Attrs: vt.AttrBold,
+		}, cells[1].Style)
+		assert.Equal(t, vt.RGBColor(4, 5, 6), cells[2].Fg)
+		assert.Equal(t, vt.Style{}, cells[3].Style)
+	})
+
+	t.Run("Erases with the background color", func(t *testing.T) {
+		term := vt.New(3, 1)
+		term.WriteString("\x1b[41m\x1b[K")
+
+		for _, cell := range term.Line(0).Cells {
+			assert.Equal(t, vt.IndexedColor(1), cell.Bg)
+		}
+	})
+}
+
+func TestTerminal_AltScreen(t *testing.T) {
+	term := vt.New(5, 2)
+	term.WriteString("main\x1b[?1049h")
+
+	assert.True(t, term.AltScreen())
+	assert.Equal(t, "\n", term.String())
+
+	term.WriteString("\x1b[Halt")
+	assert.Equal(t, "alt\n", term.String())
+
+	term.WriteString("\x1b[?1049l")
+	assert.False(t, term.AltScreen())
+	assert.Equal(t, "main\n", term.String())
+
+	x, y := term.Cursor()
+	assert.Equal(t, 4, x)
+	assert.Equal(t, 0, y)
+}
+
+func TestTerminal_Title(t *testing.T) {
+	term := vt.New(5, 1)
+	term.WriteString("\x1b]0;first\x07\x1b]2;second\x1b\\x")
+
+	assert.Equal(t, "second", term.Title())
+	assert.Equal(t, "x", term.String())
+}
+
+func TestTerminal_Resize(t *testing.T) {
+	t.Run("Drops blank lines below the cursor", func(t *testing.T) {
+		term := vt.New(5, 4)
+		term.WriteString("a\r\nb")
+		term.Resize(3, 2)
+
+		assert.Equal(t, "a\nb", term.String())
+		assert.Equal(t, 0, term.Scrolled())
+	})
+
+	t.Run("Scrolls lines off the top", func(t *testing.T) {
+		term := vt.New(5, 3)
+		term.WriteString("a\r\nb\r\nc")
+		term.Resize(5, 2)
+
+		assert.Equal(t, "b\nc", term.String())
+		assert.Equal(t, 1, term.Scrolled())
+
+		_, y := term.Cursor()
+		assert.Equal(t, 1, y)
+	})
+
+	t.Run("Truncates and pads lines", func(t *testing.T) {
+		term := vt.New(5, 1)
+		term.WriteString("abcde")
+		term.Resize(3, 2)
+
+		assert.Equal(t, "abc\n", term.String())
+
+		term.Resize(6, 2)
+		term.WriteString("\x1b[1;6Hz")
+		assert.Equal(t, "abc  z\n", term.String())
+	})
+}
@@ -0,0 +1,92 @@
+package vt
+
+import (
+	"math"
+	"strings"
+
+	"github.com/wormbks/asciinema-edit/cast"
+)
+
+// TranscriptLine is a line of text of a transcript.
+type TranscriptLine struct {
+	// Time is the timestamp of the event that first printed text on
+	// the line.
+	Time cast.Time
+
+	Text string
+}
+
+// Transcript plays the events of a cast into a fresh terminal that keeps
+// its whole history (see `SetKeepHistory`), returning the text that the
+// viewer saw: the lines that scrolled off (or got cleared from) the
+// primary screen followed by the final screen.
+//
+// Lines that were soft-wrapped at the right margin are joined back, and
+// blank lines at the end are left out.
+func Transcript(c *cast.Cast) ([]TranscriptLine, error) {
+	t, err := newForCast(c)
+	if err != nil {
+		return nil, err
+	}
+
+	t.SetScrollbackLimit(math.MaxInt32)
+	t.SetKeepHistory(true)
+
+	for _, ev := range c.EventStream {
+		err = t.Apply(ev)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var lines []TranscriptLine
+	for _, line := range joinWrapped(append(t.scrollback[:len(t.scrollback):len(t.scrollback)], t.screen.lines...)) {
+		lines = append(lines, TranscriptLine{Time: line.time, Text: line.text})
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1].Text == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines, nil
+}
+
+// logicalLine is a line of text that may span several rows because it
+// got wrapped at the right margin.
+type logicalLine struct {
+	// row is the index of the first row of the line.
+	row  int
+	time cast.Time
+	text string
+}
+
+// joinWrapped joins rows that were soft-wrapped into logical lines,
+// without trailing blanks.
+func joinWrapped(rows []Line) []logicalLine {
+	var (
+		lines []logicalLine
+		buf   strings.Builder
+		first = 0
+	)
+
+	for idx, row := range rows {
+		if row.Wrapped && idx < len(rows)-1 {
+			for _, cell := range row.Cells {
+				buf.WriteString(cell.String())
+			}
+			continue
+		}
+
+		buf.WriteString(row.String())
+		lines = append(lines, logicalLine{
+			row:  first,
+			time: rows[first].Time,
+			text: buf.String(),
+		})
+
+		first = idx + 1
+		buf.Reset()
+	}
+
+	return lines
+}
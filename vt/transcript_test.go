@@ -0,0 +1,71 @@
+package vt_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wormbks/asciinema-edit/cast"
+	"github.com/wormbks/asciinema-edit/vt"
+)
+
+func TestTranscript(t *testing.T) {
+	transcript := func(events ...*cast.Event) []vt.TranscriptLine {
+		lines, err := vt.Transcript(&cast.Cast{
+			Header:      cast.Header{Version: 2, Width: 5, Height: 3},
+			EventStream: events,
+		})
+		assert.NoError(t, err)
+
+		return lines
+	}
+
+	t.Run("Keeps the text that redraws leave", func(t *testing.T) {
+		assert.Equal(t, []vt.TranscriptLine{
+			{Time: cast.Seconds(1), Text: "done"},
+			{Time: cast.Seconds(3), Text: "$"},
+		}, transcript(
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "1\x1b[3"},
+			&cast.Event{Time: cast.Seconds(1.5), Type: "o", Data: "2m0%"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "\r50%\rdone\x1b[K"},
+			&cast.Event{Time: cast.Seconds(3), Type: "o", Data: "\r\n$\r\n\r\n"},
+		))
+	})
+
+	t.Run("Includes scrolled and cleared lines", func(t *testing.T) {
+		assert.Equal(t, []vt.TranscriptLine{
+			{Time: cast.Seconds(1), Text: "a"},
+			{Time: cast.Seconds(2), Text: "b"},
+			{Time: cast.Seconds(3), Text: "c"},
+			{Time: cast.Seconds(4), Text: "d"},
+			{Time: cast.Seconds(5), Text: "e"},
+		}, transcript(
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "a\r\n"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "b\r\n"},
+			&cast.Event{Time: cast.Seconds(3), Type: "o", Data: "c\r\n"},
+			&cast.Event{Time: cast.Seconds(4), Type: "o", Data: "d"},
+			&cast.Event{Time: cast.Seconds(5), Type: "o", Data: "\x1b[H\x1b[2J\x1b[3Je"},
+		))
+	})
+
+	t.Run("Joins wrapped lines", func(t *testing.T) {
+		assert.Equal(t, []vt.TranscriptLine{
+			{Time: cast.Seconds(1), Text: "abc  defg"},
+			{Time: cast.Seconds(2), Text: "h"},
+		}, transcript(
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "abc  defg"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "\r\nh"},
+		))
+	})
+
+	t.Run("Leaves out full-screen applications", func(t *testing.T) {
+		assert.Equal(t, []vt.TranscriptLine{
+			{Time: cast.Seconds(1), Text: "$ vi"},
+			{Time: cast.Seconds(3), Text: "$"},
+		}, transcript(
+			&cast.Event{Time: cast.Seconds(1), Type: "o", Data: "$ vi"},
+			&cast.Event{Time: cast.Seconds(2), Type: "o", Data: "\x1b[?1049h\x1b[2Jedit"},
+			&cast.Event{Time: cast.Seconds(3), Type: "o", Data: "\x1b[?1049l\r\n$"},
+		))
+	})
+}